@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Vectores de PBKDF2-HMAC-SHA256 publicados en el RFC 7914, sección 11
+func TestDerivarVectoresRFC7914(t *testing.T) {
+	casos := []struct {
+		password string
+		sal      string
+		iter     int
+		clave    string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+
+	for _, c := range casos {
+		got := hex.EncodeToString(derivar(c.password, []byte(c.sal), c.iter, 64))
+		if got != c.clave {
+			t.Errorf("derivar(%q, %q, %d) = %s, se esperaba %s", c.password, c.sal, c.iter, got, c.clave)
+		}
+	}
+}
+
+func TestCheckPassword(t *testing.T) {
+	hash, err := HashPassword("correcta-caballo-bateria")
+	if err != nil {
+		t.Fatal(err)
+	}
+	partes := strings.Split(hash, separadorHash)
+
+	casos := []struct {
+		nombre   string
+		hash     string
+		password string
+		valida   bool
+	}{
+		{"contraseña correcta", hash, "correcta-caballo-bateria", true},
+		{"contraseña incorrecta", hash, "correcta-caballo-bateriA", false},
+		{"contraseña vacía", hash, "", false},
+		{"otro algoritmo", strings.Replace(hash, prefijoHash, "pbkdf2-sha1", 1), "correcta-caballo-bateria", false},
+		{"iteraciones distintas", strings.Join([]string{partes[0], "1000", partes[2], partes[3]}, separadorHash), "correcta-caballo-bateria", false},
+		{"iteraciones inválidas", strings.Join([]string{partes[0], "0", partes[2], partes[3]}, separadorHash), "correcta-caballo-bateria", false},
+		{"sal inválida", strings.Join([]string{partes[0], partes[1], "***", partes[3]}, separadorHash), "correcta-caballo-bateria", false},
+		{"partes faltantes", strings.Join(partes[:3], separadorHash), "correcta-caballo-bateria", false},
+		{"hash vacío", "", "correcta-caballo-bateria", false},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			if got := CheckPassword(c.hash, c.password); got != c.valida {
+				t.Errorf("CheckPassword() = %v, se esperaba %v", got, c.valida)
+			}
+		})
+	}
+}
+
+// El hash ficticio debe costar lo mismo que uno real para no revelar qué usuarios existen
+func TestHashFicticio(t *testing.T) {
+	partes := strings.Split(hashFicticio, separadorHash)
+	if len(partes) != 4 || partes[0] != prefijoHash || partes[1] != strconv.Itoa(iteraciones) {
+		t.Fatalf("hashFicticio no usa los parámetros de HashPassword: %s", hashFicticio)
+	}
+	sal, err := base64.RawStdEncoding.DecodeString(partes[2])
+	if err != nil || len(sal) != largoSal {
+		t.Errorf("hashFicticio tiene una sal inválida: %v", err)
+	}
+	clave, err := base64.RawStdEncoding.DecodeString(partes[3])
+	if err != nil || len(clave) != largoClave {
+		t.Errorf("hashFicticio tiene una clave inválida: %v", err)
+	}
+}
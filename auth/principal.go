@@ -0,0 +1,45 @@
+package auth
+
+import "context"
+
+// Roles de los usuarios del sistema
+const (
+	RolEstudiante = "estudiante"
+	RolProfesor   = "profesor"
+	RolSecretaria = "secretaria"
+	RolAdmin      = "admin"
+)
+
+// Principal representa al usuario autenticado de una solicitud
+type Principal struct {
+	IDUsuario string `json:"id_usuarios"`
+	Usuario   string `json:"usuario"`
+	Rol       string `json:"rol"`
+	// IDReferencia es el id_estudiantes o id_profesores asociado al usuario, si lo tiene
+	IDReferencia string `json:"id_referencia,omitempty"`
+	// Sesion es el hash del token con el que se autenticó la solicitud
+	Sesion string `json:"-"`
+}
+
+type principalKey struct{}
+
+// WithPrincipal agrega el usuario autenticado al contexto
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext obtiene el usuario autenticado del contexto, si existe
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// TieneRol indica si el usuario tiene alguno de los roles indicados
+func (p Principal) TieneRol(roles ...string) bool {
+	for _, rol := range roles {
+		if p.Rol == rol {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"server_estudiantes/config"
+	"strings"
+	"time"
+)
+
+// DuracionSesionDefecto es el tiempo de vida de una sesión cuando AUTH_SESSION_TTL no está definido
+const DuracionSesionDefecto = 12 * time.Hour
+
+// MinSecretLen es la longitud mínima de AUTH_SECRET
+const MinSecretLen = 32
+
+// Signer emite y verifica tokens de sesión opacos firmados con HMAC-SHA256.
+// El token solo identifica la sesión; su validez final depende de que la sesión exista en la base de datos.
+type Signer struct {
+	secret   []byte
+	Duracion time.Duration
+}
+
+// NewSigner crea un firmador con el secreto y la duración de sesión indicados
+func NewSigner(secret []byte, duracion time.Duration) *Signer {
+	return &Signer{secret: secret, Duracion: duracion}
+}
+
+// LoadSigner crea un firmador a partir de AUTH_SECRET y AUTH_SESSION_TTL. Falla si el
+// secreto no está definido, es corto o conserva el valor de ejemplo: con un secreto
+// conocido cualquiera podría firmar tokens
+func LoadSigner() (*Signer, error) {
+	duracion := DuracionSesionDefecto
+	if raw := os.Getenv("AUTH_SESSION_TTL"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, err
+		}
+		duracion = d
+	}
+
+	secret := os.Getenv("AUTH_SECRET")
+	switch {
+	case secret == "":
+		return nil, errors.New("AUTH_SECRET no está definido")
+	case config.EsValorDeEjemplo(secret):
+		return nil, errors.New("AUTH_SECRET conserva el valor de ejemplo; defina un secreto propio")
+	case len(secret) < MinSecretLen:
+		return nil, fmt.Errorf("AUTH_SECRET debe tener al menos %d caracteres", MinSecretLen)
+	}
+
+	return NewSigner([]byte(secret), duracion), nil
+}
+
+// NewToken genera un token de sesión y el hash con el que se guarda en la base de datos
+func (s *Signer) NewToken() (token, hash string, err error) {
+	id := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", err
+	}
+
+	parte := base64.RawURLEncoding.EncodeToString(id)
+	token = parte + "." + s.firmar(parte)
+	return token, HashToken(token), nil
+}
+
+// Verify comprueba la firma de un token y devuelve el hash con el que buscar la sesión
+func (s *Signer) Verify(token string) (hash string, ok bool) {
+	parte, firma, found := strings.Cut(token, ".")
+	if !found || parte == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(firma), []byte(s.firmar(parte))) {
+		return "", false
+	}
+	return HashToken(token), true
+}
+
+func (s *Signer) firmar(parte string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(parte))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// HashToken calcula el hash SHA-256 con el que se almacena un token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
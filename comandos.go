@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"server_estudiantes/config"
+	"server_estudiantes/migraciones"
+	"server_estudiantes/repository"
+	"server_estudiantes/semilla"
+	"sort"
+	"strconv"
+)
+
+// usoMigrate describe los argumentos aceptados por el subcomando migrate
+const usoMigrate = "uso: server_estudiantes migrate [up | down [pasos] | status]"
+
+// ejecutarComando atiende los subcomandos de línea de comandos en lugar de iniciar el servidor
+func ejecutarComando(db *sql.DB, args []string) error {
+	switch args[0] {
+	case "migrate":
+		return comandoMigrate(db, args[1:])
+	case "seed":
+		return comandoSeed(db, args[1:])
+	default:
+		return fmt.Errorf("subcomando desconocido %q", args[0])
+	}
+}
+
+// comandoMigrate aplica, revierte o lista las migraciones del esquema
+func comandoMigrate(db *sql.DB, args []string) error {
+	ctx := context.Background()
+	accion := "up"
+	if len(args) > 0 {
+		accion = args[0]
+	}
+
+	switch accion {
+	case "up":
+		n, err := migraciones.Subir(ctx, db)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migraciones aplicadas\n", n)
+	case "down":
+		pasos := 1
+		if len(args) > 1 {
+			var err error
+			if pasos, err = strconv.Atoi(args[1]); err != nil || pasos <= 0 {
+				return fmt.Errorf("%s", usoMigrate)
+			}
+		}
+		n, err := migraciones.Bajar(ctx, db, pasos)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%d migraciones revertidas\n", n)
+	case "status":
+		estados, err := migraciones.Estados(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, e := range estados {
+			aplicada := "pendiente"
+			if e.Aplicada != nil {
+				aplicada = "aplicada " + e.Aplicada.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", e.Version, e.Nombre, aplicada)
+		}
+	default:
+		return fmt.Errorf("%s", usoMigrate)
+	}
+	return nil
+}
+
+// comandoSeed carga los datos de demostración incluidos o los del archivo JSON indicado;
+// antes aplica las migraciones pendientes para poder usarse sobre una base vacía
+func comandoSeed(db *sql.DB, args []string) error {
+	ctx := context.Background()
+	ruta := ""
+	if len(args) > 0 {
+		ruta = args[0]
+	}
+
+	fixture, err := semilla.Leer(ruta)
+	if err != nil {
+		return err
+	}
+	politica, err := config.LoadPoliticaCalificacion()
+	if err != nil {
+		return err
+	}
+	reglas, err := config.LoadReglasMatricula()
+	if err != nil {
+		return err
+	}
+	if _, err := migraciones.Subir(ctx, db); err != nil {
+		return err
+	}
+
+	resumen, err := semilla.Aplicar(ctx, repository.NewMySQLStore(db, politica, reglas), fixture)
+	if err != nil {
+		return err
+	}
+
+	entidades := map[string]bool{}
+	for e := range resumen.Creados {
+		entidades[e] = true
+	}
+	for e := range resumen.Existentes {
+		entidades[e] = true
+	}
+	nombres := make([]string, 0, len(entidades))
+	for e := range entidades {
+		nombres = append(nombres, e)
+	}
+	sort.Strings(nombres)
+	for _, e := range nombres {
+		fmt.Printf("%-14s %d creados, %d existentes\n", e, resumen.Creados[e], resumen.Existentes[e])
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+)
+
+// Modos de uso de la nota de supletorio
+const (
+	SupletorioReemplaza = "reemplaza"
+	SupletorioPromedia  = "promedia"
+)
+
+// Estados posibles de un registro de notas
+const (
+	EstadoAprobado   = "aprobado"
+	EstadoReprobado  = "reprobado"
+	EstadoSupletorio = "supletorio"
+	// EstadoEnCurso es un registro aún sin calificar, como el que crea una matrícula nueva
+	EstadoEnCurso = "en_curso"
+)
+
+// Finalizado indica si un estado es un resultado definitivo de la asignatura. Un registro
+// sin calificar o con el supletorio pendiente sigue en curso: no cuenta como aprobada ni
+// reprobada en el kardex ni en el historial que usan las reglas de matrícula.
+func Finalizado(estado string) bool {
+	return estado == EstadoAprobado || estado == EstadoReprobado
+}
+
+// PoliticaCalificacion define cómo se calcula la nota final de un registro de notas
+type PoliticaCalificacion struct {
+	NotaMaxima           float64
+	Peso1                float64
+	Peso2                float64
+	NotaAprobacion       float64
+	NotaMinimaSupletorio float64
+	ModoSupletorio       string
+}
+
+// LoadPoliticaCalificacion carga la política de calificación desde las variables de entorno
+func LoadPoliticaCalificacion() (PoliticaCalificacion, error) {
+	p := PoliticaCalificacion{
+		NotaMaxima:           10,
+		Peso1:                0.5,
+		Peso2:                0.5,
+		NotaAprobacion:       7,
+		NotaMinimaSupletorio: 5,
+		ModoSupletorio:       SupletorioReemplaza,
+	}
+
+	campos := []struct {
+		env   string
+		valor *float64
+	}{
+		{"NOTA_MAXIMA", &p.NotaMaxima},
+		{"NOTA_PESO1", &p.Peso1},
+		{"NOTA_PESO2", &p.Peso2},
+		{"NOTA_APROBACION", &p.NotaAprobacion},
+		{"NOTA_MINIMA_SUPLETORIO", &p.NotaMinimaSupletorio},
+	}
+	for _, campo := range campos {
+		raw := os.Getenv(campo.env)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return p, fmt.Errorf("valor inválido para %s: %w", campo.env, err)
+		}
+		*campo.valor = v
+	}
+
+	if modo := os.Getenv("NOTA_SUPLETORIO_MODO"); modo != "" {
+		p.ModoSupletorio = modo
+	}
+
+	if err := p.validar(); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// validar verifica que la política sea coherente
+func (p PoliticaCalificacion) validar() error {
+	if p.NotaMaxima <= 0 {
+		return fmt.Errorf("la nota máxima debe ser mayor que cero")
+	}
+	if p.Peso1 < 0 || p.Peso2 < 0 || p.Peso1+p.Peso2 == 0 {
+		return fmt.Errorf("los pesos de las notas deben ser positivos")
+	}
+	if p.NotaAprobacion <= 0 || p.NotaAprobacion > p.NotaMaxima {
+		return fmt.Errorf("la nota de aprobación debe estar entre 0 y %g", p.NotaMaxima)
+	}
+	if p.NotaMinimaSupletorio < 0 || p.NotaMinimaSupletorio > p.NotaAprobacion {
+		return fmt.Errorf("la nota mínima de supletorio debe estar entre 0 y la nota de aprobación")
+	}
+	if p.ModoSupletorio != SupletorioReemplaza && p.ModoSupletorio != SupletorioPromedia {
+		return fmt.Errorf("modo de supletorio desconocido: %s", p.ModoSupletorio)
+	}
+	return nil
+}
+
+// Evaluar calcula el promedio, la nota final y el estado de un registro de notas.
+// Un registro que aún no fue calificado sigue en curso sin importar sus notas; en uno
+// calificado, una nota de supletorio igual a cero significa que el estudiante aún no lo
+// rinde. Es la única regla que decide el estado de un registro: las notas, el kardex y la
+// matrícula la comparten.
+func (p PoliticaCalificacion) Evaluar(calificado bool, nota1, nota2 float64, sup int) (promedio, notaFinal float64, estado string) {
+	if !calificado {
+		return 0, 0, EstadoEnCurso
+	}
+
+	promedio = redondear((p.Peso1*nota1 + p.Peso2*nota2) / (p.Peso1 + p.Peso2))
+
+	switch {
+	case promedio >= p.NotaAprobacion:
+		return promedio, promedio, EstadoAprobado
+	case promedio < p.NotaMinimaSupletorio:
+		return promedio, promedio, EstadoReprobado
+	case sup == 0:
+		return promedio, promedio, EstadoSupletorio
+	}
+
+	notaFinal = float64(sup)
+	if p.ModoSupletorio == SupletorioPromedia {
+		notaFinal = redondear((promedio + notaFinal) / 2)
+	}
+
+	if notaFinal >= p.NotaAprobacion {
+		return promedio, notaFinal, EstadoAprobado
+	}
+	return promedio, notaFinal, EstadoReprobado
+}
+
+// redondear deja un valor con dos decimales
+func redondear(v float64) float64 {
+	return math.Round(v*100) / 100
+}
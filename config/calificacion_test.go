@@ -0,0 +1,73 @@
+package config
+
+import "testing"
+
+func TestEvaluar(t *testing.T) {
+	reemplaza := PoliticaCalificacion{
+		NotaMaxima:           10,
+		Peso1:                0.5,
+		Peso2:                0.5,
+		NotaAprobacion:       7,
+		NotaMinimaSupletorio: 5,
+		ModoSupletorio:       SupletorioReemplaza,
+	}
+	promedia := reemplaza
+	promedia.ModoSupletorio = SupletorioPromedia
+	ponderada := reemplaza
+	ponderada.Peso1, ponderada.Peso2 = 0.3, 0.7
+
+	casos := []struct {
+		nombre       string
+		politica     PoliticaCalificacion
+		calificado   bool
+		nota1, nota2 float64
+		sup          int
+		promedio     float64
+		notaFinal    float64
+		estado       string
+	}{
+		{"sin calificar", reemplaza, false, 0, 0, 0, 0, 0, EstadoEnCurso},
+		{"sin calificar con notas cargadas", reemplaza, false, 8, 9, 0, 0, 0, EstadoEnCurso},
+		{"calificado con cero", reemplaza, true, 0, 0, 0, 0, 0, EstadoReprobado},
+		{"aprobado", reemplaza, true, 8, 9, 0, 8.5, 8.5, EstadoAprobado},
+		{"aprobado en el límite", reemplaza, true, 7, 7, 0, 7, 7, EstadoAprobado},
+		{"reprobado sin supletorio", reemplaza, true, 3, 4, 0, 3.5, 3.5, EstadoReprobado},
+		{"una nota en cero", reemplaza, true, 0, 8, 0, 4, 4, EstadoReprobado},
+		{"supletorio pendiente", reemplaza, true, 6, 5, 0, 5.5, 5.5, EstadoSupletorio},
+		{"supletorio en el límite inferior", reemplaza, true, 5, 5, 0, 5, 5, EstadoSupletorio},
+		{"supletorio aprobado reemplaza", reemplaza, true, 6, 5, 8, 5.5, 8, EstadoAprobado},
+		{"supletorio reprobado reemplaza", reemplaza, true, 6, 5, 6, 5.5, 6, EstadoReprobado},
+		{"supletorio reprobado promedia", promedia, true, 6, 5, 8, 5.5, 6.75, EstadoReprobado},
+		{"supletorio aprobado promedia", promedia, true, 6, 5, 9, 5.5, 7.25, EstadoAprobado},
+		{"supletorio sin notas parciales", reemplaza, true, 0, 0, 9, 0, 0, EstadoReprobado},
+		{"pesos distintos y redondeo", ponderada, true, 6.67, 7.33, 0, 7.13, 7.13, EstadoAprobado},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			promedio, notaFinal, estado := c.politica.Evaluar(c.calificado, c.nota1, c.nota2, c.sup)
+			if promedio != c.promedio || notaFinal != c.notaFinal || estado != c.estado {
+				t.Errorf("Evaluar(%v, %g, %g, %d) = (%g, %g, %s), se esperaba (%g, %g, %s)",
+					c.calificado, c.nota1, c.nota2, c.sup, promedio, notaFinal, estado, c.promedio, c.notaFinal, c.estado)
+			}
+		})
+	}
+}
+
+func TestFinalizado(t *testing.T) {
+	casos := []struct {
+		estado     string
+		finalizado bool
+	}{
+		{EstadoAprobado, true},
+		{EstadoReprobado, true},
+		{EstadoSupletorio, false},
+		{EstadoEnCurso, false},
+	}
+
+	for _, c := range casos {
+		if got := Finalizado(c.estado); got != c.finalizado {
+			t.Errorf("Finalizado(%s) = %v, se esperaba %v", c.estado, got, c.finalizado)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ReglasMatricula define los límites configurables de la matrícula
+type ReglasMatricula struct {
+	// MaxCreditosCiclo es el máximo de créditos que un estudiante puede matricular
+	// en un mismo ciclo; cero desactiva el límite
+	MaxCreditosCiclo int
+}
+
+// LoadReglasMatricula carga los límites de la matrícula desde las variables de entorno
+func LoadReglasMatricula() (ReglasMatricula, error) {
+	var r ReglasMatricula
+
+	if raw := os.Getenv("MAX_CREDITOS_CICLO"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return r, fmt.Errorf("valor inválido para MAX_CREDITOS_CICLO: %w", err)
+		}
+		if v < 0 {
+			return r, fmt.Errorf("MAX_CREDITOS_CICLO no puede ser negativo")
+		}
+		r.MaxCreditosCiclo = v
+	}
+	return r, nil
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ConfigOutbox define cómo se envían al middleware los eventos de la tabla outbox
+type ConfigOutbox struct {
+	// Intervalo entre consultas de eventos pendientes
+	Intervalo time.Duration
+	// Lote es la cantidad máxima de eventos que se envían por consulta
+	Lote int
+	// MaxIntentos es la cantidad de envíos fallidos tras la cual el evento pasa a fallido
+	MaxIntentos int
+	// EsperaInicial y EsperaMaxima acotan el retroceso exponencial entre reintentos
+	EsperaInicial time.Duration
+	EsperaMaxima  time.Duration
+}
+
+// LoadConfigOutbox carga la configuración del despachador desde las variables de entorno
+func LoadConfigOutbox() (ConfigOutbox, error) {
+	c := ConfigOutbox{
+		Intervalo:     2 * time.Second,
+		Lote:          50,
+		MaxIntentos:   10,
+		EsperaInicial: time.Second,
+		EsperaMaxima:  5 * time.Minute,
+	}
+
+	duraciones := []struct {
+		env   string
+		valor *time.Duration
+	}{
+		{"OUTBOX_INTERVALO", &c.Intervalo},
+		{"OUTBOX_ESPERA_INICIAL", &c.EsperaInicial},
+		{"OUTBOX_ESPERA_MAXIMA", &c.EsperaMaxima},
+	}
+	for _, campo := range duraciones {
+		raw := os.Getenv(campo.env)
+		if raw == "" {
+			continue
+		}
+		v, err := time.ParseDuration(raw)
+		if err != nil {
+			return c, fmt.Errorf("valor inválido para %s: %w", campo.env, err)
+		}
+		*campo.valor = v
+	}
+
+	enteros := []struct {
+		env   string
+		valor *int
+	}{
+		{"OUTBOX_LOTE", &c.Lote},
+		{"OUTBOX_MAX_INTENTOS", &c.MaxIntentos},
+	}
+	for _, campo := range enteros {
+		raw := os.Getenv(campo.env)
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return c, fmt.Errorf("valor inválido para %s: %w", campo.env, err)
+		}
+		*campo.valor = v
+	}
+
+	if c.Intervalo <= 0 || c.EsperaInicial <= 0 || c.EsperaMaxima < c.EsperaInicial {
+		return c, fmt.Errorf("los intervalos del outbox deben ser positivos y la espera máxima no menor que la inicial")
+	}
+	if c.Lote <= 0 || c.MaxIntentos <= 0 {
+		return c, fmt.Errorf("el lote y la cantidad de intentos del outbox deben ser mayores que cero")
+	}
+	return c, nil
+}
+
+// Espera calcula el retroceso exponencial tras el número de intentos fallidos indicado
+func (c ConfigOutbox) Espera(intentos int) time.Duration {
+	espera := c.EsperaInicial
+	for i := 1; i < intentos && espera < c.EsperaMaxima; i++ {
+		espera *= 2
+	}
+	if espera > c.EsperaMaxima {
+		espera = c.EsperaMaxima
+	}
+	return espera
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigOutboxEspera(t *testing.T) {
+	c := ConfigOutbox{EsperaInicial: time.Second, EsperaMaxima: 10 * time.Second}
+
+	casos := []struct {
+		intentos int
+		espera   time.Duration
+	}{
+		{0, time.Second},
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{6, 10 * time.Second},
+		{1000, 10 * time.Second},
+	}
+
+	for _, caso := range casos {
+		if got := c.Espera(caso.intentos); got != caso.espera {
+			t.Errorf("Espera(%d) = %v, se esperaba %v", caso.intentos, got, caso.espera)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import "os"
+
+// valoresDeEjemplo son los valores de relleno que llegaron a publicarse en el
+// repositorio; un secreto que conserva alguno de ellos se trata como no configurado
+var valoresDeEjemplo = map[string]bool{
+	"cambiar-por-un-secreto-largo-y-aleatorio": true,
+	"cambiar-esta-clave":                       true,
+	"cambiar-por-el-secreto-del-middleware":    true,
+}
+
+// EsValorDeEjemplo indica si un secreto conserva un valor de relleno publicado
+func EsValorDeEjemplo(valor string) bool {
+	return valoresDeEjemplo[valor]
+}
+
+// SyncToken devuelve el secreto compartido con el middleware, o "" si SYNC_TOKEN no está
+// definido o conserva el valor de ejemplo; sin secreto el receptor /sync queda deshabilitado
+func SyncToken() string {
+	token := os.Getenv("SYNC_TOKEN")
+	if EsValorDeEjemplo(token) {
+		return ""
+	}
+	return token
+}
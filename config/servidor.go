@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Version identifica la compilación; se define al compilar con
+// -ldflags "-X server_estudiantes/config.Version=<versión>"
+var Version = "dev"
+
+// ConfigServidor define los tiempos del ciclo de vida del servidor
+type ConfigServidor struct {
+	// HeartbeatIntervalo es el tiempo entre heartbeats enviados al middleware
+	HeartbeatIntervalo time.Duration
+	// ShutdownTimeout es el tiempo máximo para drenar las solicitudes al detenerse
+	ShutdownTimeout time.Duration
+	// MigrarAlIniciar aplica las migraciones pendientes del esquema al arrancar
+	MigrarAlIniciar bool
+}
+
+// LoadConfigServidor carga la configuración del servidor desde las variables de entorno
+func LoadConfigServidor() (ConfigServidor, error) {
+	c := ConfigServidor{
+		HeartbeatIntervalo: 30 * time.Second,
+		ShutdownTimeout:    15 * time.Second,
+		MigrarAlIniciar:    true,
+	}
+
+	campos := []struct {
+		env   string
+		valor *time.Duration
+	}{
+		{"HEARTBEAT_INTERVALO", &c.HeartbeatIntervalo},
+		{"SHUTDOWN_TIMEOUT", &c.ShutdownTimeout},
+	}
+	for _, campo := range campos {
+		raw := os.Getenv(campo.env)
+		if raw == "" {
+			continue
+		}
+		v, err := time.ParseDuration(raw)
+		if err != nil {
+			return c, fmt.Errorf("valor inválido para %s: %w", campo.env, err)
+		}
+		if v <= 0 {
+			return c, fmt.Errorf("%s debe ser mayor que cero", campo.env)
+		}
+		*campo.valor = v
+	}
+
+	if raw := os.Getenv("MIGRAR_AL_INICIAR"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return c, fmt.Errorf("valor inválido para MIGRAR_AL_INICIAR: %w", err)
+		}
+		c.MigrarAlIniciar = v
+	}
+	return c, nil
+}
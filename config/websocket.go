@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigWebSocket define los límites de las conexiones WebSocket
+type ConfigWebSocket struct {
+	// Origenes permitidos; vacío solo acepta el mismo host y "*" acepta cualquiera
+	Origenes []string
+	// PingIntervalo es el tiempo entre pings enviados al cliente
+	PingIntervalo time.Duration
+	// PongEspera es el tiempo sin respuesta tras el cual la conexión se considera inactiva
+	PongEspera time.Duration
+	// EscrituraEspera es el tiempo máximo para escribir un mensaje
+	EscrituraEspera time.Duration
+	// MaxMensaje es el tamaño máximo en bytes de un mensaje recibido
+	MaxMensaje int64
+	// ColaEnvio es la cantidad de mensajes pendientes por cliente; si se llena, el cliente se desconecta
+	ColaEnvio int
+}
+
+// LoadConfigWebSocket carga la configuración de WebSocket desde las variables de entorno
+func LoadConfigWebSocket() (ConfigWebSocket, error) {
+	c := ConfigWebSocket{
+		PingIntervalo:   30 * time.Second,
+		PongEspera:      60 * time.Second,
+		EscrituraEspera: 10 * time.Second,
+		MaxMensaje:      4096,
+		ColaEnvio:       32,
+	}
+
+	if raw := os.Getenv("WS_ORIGENES"); raw != "" {
+		for _, origen := range strings.Split(raw, ",") {
+			if origen = strings.TrimSpace(origen); origen != "" {
+				c.Origenes = append(c.Origenes, origen)
+			}
+		}
+	}
+
+	duraciones := []struct {
+		env   string
+		valor *time.Duration
+	}{
+		{"WS_PING_INTERVALO", &c.PingIntervalo},
+		{"WS_PONG_ESPERA", &c.PongEspera},
+		{"WS_ESCRITURA_ESPERA", &c.EscrituraEspera},
+	}
+	for _, campo := range duraciones {
+		raw := os.Getenv(campo.env)
+		if raw == "" {
+			continue
+		}
+		v, err := time.ParseDuration(raw)
+		if err != nil {
+			return c, fmt.Errorf("valor inválido para %s: %w", campo.env, err)
+		}
+		*campo.valor = v
+	}
+
+	if raw := os.Getenv("WS_MAX_MENSAJE"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return c, fmt.Errorf("valor inválido para WS_MAX_MENSAJE: %w", err)
+		}
+		c.MaxMensaje = v
+	}
+	if raw := os.Getenv("WS_COLA_ENVIO"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return c, fmt.Errorf("valor inválido para WS_COLA_ENVIO: %w", err)
+		}
+		c.ColaEnvio = v
+	}
+
+	if c.PingIntervalo <= 0 || c.EscrituraEspera <= 0 || c.PongEspera <= c.PingIntervalo {
+		return c, fmt.Errorf("los tiempos de WebSocket deben ser positivos y WS_PONG_ESPERA mayor que WS_PING_INTERVALO")
+	}
+	if c.MaxMensaje <= 0 || c.ColaEnvio <= 0 {
+		return c, fmt.Errorf("WS_MAX_MENSAJE y WS_COLA_ENVIO deben ser mayores que cero")
+	}
+	return c, nil
+}
+
+// OrigenPermitido indica si el origen está en la lista de orígenes permitidos
+func (c ConfigWebSocket) OrigenPermitido(origen string) bool {
+	for _, permitido := range c.Origenes {
+		if permitido == "*" || strings.EqualFold(permitido, origen) {
+			return true
+		}
+	}
+	return false
+}
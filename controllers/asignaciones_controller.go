@@ -1,73 +1,47 @@
 package controllers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"server_estudiantes/auth"
 	"server_estudiantes/models"
+	"server_estudiantes/repository"
 
 	"github.com/gorilla/mux"
 )
 
 // AsignacionesController maneja las solicitudes relacionadas con asignaciones
 type AsignacionesController struct {
-	DB *sql.DB
+	Repo           repository.AsignacionRepo
+	Prerrequisitos repository.PrerrequisitoRepo
 }
 
 // NewAsignacionesController crea una nueva instancia del controlador de asignaciones
-func NewAsignacionesController(db *sql.DB) *AsignacionesController {
-	return &AsignacionesController{DB: db}
+func NewAsignacionesController(repo repository.AsignacionRepo, prerrequisitos repository.PrerrequisitoRepo) *AsignacionesController {
+	return &AsignacionesController{Repo: repo, Prerrequisitos: prerrequisitos}
 }
 
-// GetAllAsignaciones obtiene todas las asignaciones
+// GetAllAsignaciones obtiene una página de asignaciones filtrada y ordenada
 func (c *AsignacionesController) GetAllAsignaciones(w http.ResponseWriter, r *http.Request) {
-	rows, err := c.DB.Query(`
-		SELECT 
-			pca.id_, 
-			pca.id_profesores_ciclos_asignaturas, 
-			pca.id_profesores, 
-			pca.id_asignaturas, 
-			pca.id_ciclos, 
-			pca.version,
-			p.nombre AS nombre_profesor,
-			a.nombre_asignatura,
-			c.ciclo
-		FROM profesores_ciclos_asignaturas pca
-		JOIN profesores p ON pca.id_profesores = p.id_profesores
-		JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
-		JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
-	`)
+	p, err := leerListParams(r)
 	if err != nil {
+		responderError(w, r, http.StatusBadRequest, models.CodParametroInvalido, err.Error())
+		return
+	}
+
+	asignaciones, total, siguiente, err := c.Repo.List(r.Context(), p)
+	if errors.Is(err, repository.ErrInvalidParam) {
+		responderError(w, r, http.StatusBadRequest, models.CodParametroInvalido, err.Error())
+		return
+	} else if err != nil {
 		log.Printf("Error al consultar asignaciones: %v", err)
-		http.Error(w, "Error al obtener asignaciones", http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	asignaciones := []models.Asignacion{}
-	for rows.Next() {
-		var a models.Asignacion
-		if err := rows.Scan(
-			&a.ID, 
-			&a.IDAsignacion, 
-			&a.IDProfesor, 
-			&a.IDAsignatura, 
-			&a.IDCiclo, 
-			&a.Version,
-			&a.NombreProfesor,
-			&a.NombreAsignatura,
-			&a.Ciclo,
-		); err != nil {
-			log.Printf("Error al escanear asignación: %v", err)
-			http.Error(w, "Error al procesar datos de asignaciones", http.StatusInternalServerError)
-			return
-		}
-		asignaciones = append(asignaciones, a)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener asignaciones")
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(asignaciones)
+	responderPagina(w, asignaciones, total, siguiente, p)
 }
 
 // GetAsignacion obtiene una asignación por su ID
@@ -75,84 +49,218 @@ func (c *AsignacionesController) GetAsignacion(w http.ResponseWriter, r *http.Re
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var a models.Asignacion
-	err := c.DB.QueryRow(`
-		SELECT 
-			pca.id_, 
-			pca.id_profesores_ciclos_asignaturas, 
-			pca.id_profesores, 
-			pca.id_asignaturas, 
-			pca.id_ciclos, 
-			pca.version,
-			p.nombre AS nombre_profesor,
-			a.nombre_asignatura,
-			c.ciclo
-		FROM profesores_ciclos_asignaturas pca
-		JOIN profesores p ON pca.id_profesores = p.id_profesores
-		JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
-		JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
-		WHERE pca.id_profesores_ciclos_asignaturas = ?
-	`, id).Scan(
-		&a.ID, 
-		&a.IDAsignacion, 
-		&a.IDProfesor, 
-		&a.IDAsignatura, 
-		&a.IDCiclo, 
-		&a.Version,
-		&a.NombreProfesor,
-		&a.NombreAsignatura,
-		&a.Ciclo,
-	)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Asignación no encontrada", http.StatusNotFound)
+	a, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodAsignacionNoEncontrada, "Asignación no encontrada")
 		return
 	} else if err != nil {
 		log.Printf("Error al consultar asignación: %v", err)
-		http.Error(w, "Error al obtener asignación", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener asignación")
 		return
 	}
 
+	setETag(w, a.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(a)
 }
 
-// GetAsignaturasDisponibles obtiene todas las asignaturas disponibles para matricularse
+// GetAsignaturasDisponibles obtiene las asignaciones de las asignaturas a las que puede
+// optar un estudiante: las que aún no aprobó y cuyos prerrequisitos ya aprobó. Un
+// estudiante siempre consulta las propias; el personal puede indicar ?id_estudiantes=
+// y, si no lo hace, obtiene todas las asignaciones
 func (c *AsignacionesController) GetAsignaturasDisponibles(w http.ResponseWriter, r *http.Request) {
-	rows, err := c.DB.Query(`
-		SELECT 
-			pca.id_profesores_ciclos_asignaturas, 
-			p.nombre AS nombre_profesor,
-			a.nombre_asignatura,
-			c.ciclo
-		FROM profesores_ciclos_asignaturas pca
-		JOIN profesores p ON pca.id_profesores = p.id_profesores
-		JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
-		JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
-	`)
+	idEstudiante := r.URL.Query().Get("id_estudiantes")
+	if p, _ := auth.PrincipalFromContext(r.Context()); p.Rol == auth.RolEstudiante {
+		idEstudiante = p.IDReferencia
+	}
+
+	asignaciones, _, _, err := c.Repo.List(r.Context(), repository.ListParams{})
 	if err != nil {
 		log.Printf("Error al consultar asignaturas disponibles: %v", err)
-		http.Error(w, "Error al obtener asignaturas disponibles", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener asignaturas disponibles")
 		return
 	}
-	defer rows.Close()
 
-	asignaturas := []map[string]interface{}{}
-	for rows.Next() {
-		var id, profesor, asignatura, ciclo string
-		if err := rows.Scan(&id, &profesor, &asignatura, &ciclo); err != nil {
-			log.Printf("Error al escanear asignatura disponible: %v", err)
-			http.Error(w, "Error al procesar datos de asignaturas disponibles", http.StatusInternalServerError)
+	var elegibles map[string]bool
+	if idEstudiante != "" {
+		asignaturasElegibles, err := c.Prerrequisitos.Elegibles(r.Context(), idEstudiante)
+		if errors.Is(err, repository.ErrNotFound) {
+			responderError(w, r, http.StatusNotFound, models.CodEstudianteNoEncontrado, "Estudiante no encontrado")
 			return
+		} else if err != nil {
+			log.Printf("Error al consultar asignaturas elegibles: %v", err)
+			responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener asignaturas disponibles")
+			return
+		}
+		elegibles = map[string]bool{}
+		for _, a := range asignaturasElegibles {
+			elegibles[a.IDAsignatura] = true
+		}
+	}
+
+	asignaturas := []map[string]interface{}{}
+	for _, a := range asignaciones {
+		if elegibles != nil && !elegibles[a.IDAsignatura] {
+			continue
 		}
 		asignaturas = append(asignaturas, map[string]interface{}{
-			"id": id,
-			"profesor": profesor,
-			"asignatura": asignatura,
-			"ciclo": ciclo,
+			"id":         a.IDAsignacion,
+			"profesor":   a.NombreProfesor,
+			"asignatura": a.NombreAsignatura,
+			"ciclo":      a.Ciclo,
 		})
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(asignaturas)
 }
+
+// GetAsignaturasDisponiblesByEstudiante obtiene las asignaciones en las que el estudiante
+// puede matricularse ahora, con los cupos que quedan en cada una
+func (c *AsignacionesController) GetAsignaturasDisponiblesByEstudiante(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idEstudiante := vars["id"]
+
+	disponibles, err := c.Repo.Disponibles(r.Context(), idEstudiante)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodEstudianteNoEncontrado, "Estudiante no encontrado")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar asignaturas disponibles del estudiante: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener asignaturas disponibles")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(disponibles)
+}
+
+// CreateAsignacion abre una nueva asignación vinculando profesor, asignatura y ciclo
+func (c *AsignacionesController) CreateAsignacion(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		IDProfesor   string `json:"id_profesores" validar:"requerido,id"`
+		IDAsignatura string `json:"id_asignaturas" validar:"requerido,id"`
+		IDCiclo      string `json:"id_ciclos" validar:"requerido,id"`
+		Cupo         *int   `json:"cupo" validar:"min=1"`
+	}
+
+	if !leerCuerpo(w, r, &input) {
+		return
+	}
+
+	nuevaAsignacion := models.Asignacion{
+		IDProfesor:   input.IDProfesor,
+		IDAsignatura: input.IDAsignatura,
+		IDCiclo:      input.IDCiclo,
+		Cupo:         input.Cupo,
+	}
+	err := c.Repo.Create(r.Context(), &nuevaAsignacion)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderNoEncontrado(w, r, err)
+		return
+	} else if errors.Is(err, repository.ErrDuplicate) {
+		responderError(w, r, http.StatusBadRequest, models.CodAsignacionDuplicada, "El profesor ya tiene asignada esta asignatura en este ciclo")
+		return
+	} else if err != nil {
+		log.Printf("Error al insertar asignación: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear asignación")
+		return
+	}
+
+	setETag(w, nuevaAsignacion.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(nuevaAsignacion)
+}
+
+// UpdateAsignacion reasigna el profesor y el cupo de una asignación existente
+func (c *AsignacionesController) UpdateAsignacion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var input struct {
+		IDProfesor string `json:"id_profesores" validar:"requerido,id"`
+		Cupo       *int   `json:"cupo" validar:"min=1"`
+	}
+
+	if !leerCuerpo(w, r, &input) {
+		return
+	}
+
+	// Verificar si existe la asignación
+	asignacion, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodAsignacionNoEncontrada, "Asignación no encontrada")
+		return
+	} else if err != nil {
+		log.Printf("Error al verificar asignación: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar asignación")
+		return
+	}
+
+	if !verificarIfMatch(w, r, asignacion.Version) {
+		return
+	}
+
+	// Reasignar el profesor y el cupo solo si la versión no cambió
+	asignacionActualizada := asignacion
+	asignacionActualizada.IDProfesor = input.IDProfesor
+	asignacionActualizada.Cupo = input.Cupo
+	err = c.Repo.Update(r.Context(), &asignacionActualizada)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderNoEncontrado(w, r, err)
+		return
+	} else if errors.Is(err, repository.ErrDuplicate) {
+		responderError(w, r, http.StatusBadRequest, models.CodAsignacionDuplicada, "El profesor ya tiene asignada esta asignatura en este ciclo")
+		return
+	} else if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
+		log.Printf("Error al actualizar asignación: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar asignación")
+		return
+	}
+
+	setETag(w, asignacionActualizada.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(asignacionActualizada)
+}
+
+// DeleteAsignacion cierra (elimina) una asignación
+func (c *AsignacionesController) DeleteAsignacion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Verificar si existe la asignación
+	asignacion, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodAsignacionNoEncontrada, "Asignación no encontrada")
+		return
+	} else if err != nil {
+		log.Printf("Error al verificar asignación: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar asignación")
+		return
+	}
+
+	if !verificarIfMatch(w, r, asignacion.Version) {
+		return
+	}
+
+	// Eliminar asignación si no tiene matrículas
+	err = c.Repo.Delete(r.Context(), asignacion)
+	if errors.Is(err, repository.ErrInUse) {
+		responderError(w, r, http.StatusBadRequest, models.CodAsignacionConMatriculas, "No se puede eliminar la asignación porque tiene matrículas")
+		return
+	} else if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
+		log.Printf("Error al eliminar asignación: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar asignación")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Asignación eliminada correctamente"})
+}
@@ -1,45 +1,34 @@
 package controllers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"server_estudiantes/models"
+	"server_estudiantes/repository"
 
 	"github.com/gorilla/mux"
 )
 
 // AsignaturasController maneja las solicitudes relacionadas con asignaturas
 type AsignaturasController struct {
-	DB *sql.DB
+	Repo repository.AsignaturaRepo
 }
 
 // NewAsignaturasController crea una nueva instancia del controlador de asignaturas
-func NewAsignaturasController(db *sql.DB) *AsignaturasController {
-	return &AsignaturasController{DB: db}
+func NewAsignaturasController(repo repository.AsignaturaRepo) *AsignaturasController {
+	return &AsignaturasController{Repo: repo}
 }
 
 // GetAllAsignaturas obtiene todas las asignaturas
 func (c *AsignaturasController) GetAllAsignaturas(w http.ResponseWriter, r *http.Request) {
-	rows, err := c.DB.Query("SELECT id_, id_asignaturas, nombre_asignatura, version FROM asignaturas")
+	asignaturas, err := c.Repo.List(r.Context())
 	if err != nil {
 		log.Printf("Error al consultar asignaturas: %v", err)
-		http.Error(w, "Error al obtener asignaturas", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener asignaturas")
 		return
 	}
-	defer rows.Close()
-
-	asignaturas := []models.Asignatura{}
-	for rows.Next() {
-		var a models.Asignatura
-		if err := rows.Scan(&a.ID, &a.IDAsignatura, &a.Nombre, &a.Version); err != nil {
-			log.Printf("Error al escanear asignatura: %v", err)
-			http.Error(w, "Error al procesar datos de asignaturas", http.StatusInternalServerError)
-			return
-		}
-		asignaturas = append(asignaturas, a)
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(asignaturas)
@@ -50,19 +39,142 @@ func (c *AsignaturasController) GetAsignatura(w http.ResponseWriter, r *http.Req
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var a models.Asignatura
-	err := c.DB.QueryRow("SELECT id_, id_asignaturas, nombre_asignatura, version FROM asignaturas WHERE id_asignaturas = ?", id).
-		Scan(&a.ID, &a.IDAsignatura, &a.Nombre, &a.Version)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Asignatura no encontrada", http.StatusNotFound)
+	a, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodAsignaturaNoEncontrada, "Asignatura no encontrada")
 		return
 	} else if err != nil {
 		log.Printf("Error al consultar asignatura: %v", err)
-		http.Error(w, "Error al obtener asignatura", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener asignatura")
 		return
 	}
 
+	setETag(w, a.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(a)
 }
+
+// CreateAsignatura crea una nueva asignatura
+func (c *AsignaturasController) CreateAsignatura(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Nombre         string `json:"nombre_asignatura" validar:"requerido,max=100"`
+		Creditos       *int   `json:"creditos" validar:"min=1,max=30"`
+		HorasSemanales *int   `json:"horas_semanales" validar:"min=0,max=60"`
+	}
+
+	if !leerCuerpo(w, r, &input) {
+		return
+	}
+
+	// Sin créditos indicados la asignatura vale un crédito y no declara horas
+	nuevaAsignatura := models.Asignatura{Nombre: input.Nombre, Creditos: 1}
+	if input.Creditos != nil {
+		nuevaAsignatura.Creditos = *input.Creditos
+	}
+	if input.HorasSemanales != nil {
+		nuevaAsignatura.HorasSemanales = *input.HorasSemanales
+	}
+	if err := c.Repo.Create(r.Context(), &nuevaAsignatura); err != nil {
+		log.Printf("Error al insertar asignatura: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear asignatura")
+		return
+	}
+
+	setETag(w, nuevaAsignatura.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(nuevaAsignatura)
+}
+
+// UpdateAsignatura actualiza una asignatura existente
+func (c *AsignaturasController) UpdateAsignatura(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var input struct {
+		Nombre         string `json:"nombre_asignatura" validar:"requerido,max=100"`
+		Creditos       *int   `json:"creditos" validar:"min=1,max=30"`
+		HorasSemanales *int   `json:"horas_semanales" validar:"min=0,max=60"`
+	}
+
+	if !leerCuerpo(w, r, &input) {
+		return
+	}
+
+	// Verificar si la asignatura existe
+	asignatura, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodAsignaturaNoEncontrada, "Asignatura no encontrada")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar asignatura: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar asignatura")
+		return
+	}
+
+	if !verificarIfMatch(w, r, asignatura.Version) {
+		return
+	}
+
+	// Actualizar asignatura solo si la versión no cambió
+	asignaturaActualizada := asignatura
+	asignaturaActualizada.Nombre = input.Nombre
+	// Los créditos y horas que no se envían conservan su valor
+	if input.Creditos != nil {
+		asignaturaActualizada.Creditos = *input.Creditos
+	}
+	if input.HorasSemanales != nil {
+		asignaturaActualizada.HorasSemanales = *input.HorasSemanales
+	}
+	err = c.Repo.Update(r.Context(), &asignaturaActualizada)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
+		log.Printf("Error al actualizar asignatura: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar asignatura")
+		return
+	}
+
+	setETag(w, asignaturaActualizada.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(asignaturaActualizada)
+}
+
+// DeleteAsignatura elimina una asignatura
+func (c *AsignaturasController) DeleteAsignatura(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Verificar si la asignatura existe
+	asignatura, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodAsignaturaNoEncontrada, "Asignatura no encontrada")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar asignatura: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar asignatura")
+		return
+	}
+
+	if !verificarIfMatch(w, r, asignatura.Version) {
+		return
+	}
+
+	// Eliminar asignatura si no tiene asignaciones ni prerrequisitos
+	err = c.Repo.Delete(r.Context(), asignatura)
+	if errors.Is(err, repository.ErrInUse) {
+		responderError(w, r, http.StatusBadRequest, models.CodAsignaturaEnUso, "No se puede eliminar la asignatura porque tiene asignaciones, tiene prerrequisitos o es prerrequisito de otra")
+		return
+	} else if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
+		log.Printf("Error al eliminar asignatura: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar asignatura")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Asignatura eliminada correctamente"})
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"server_estudiantes/auth"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
+	"time"
+)
+
+// AuthController maneja el inicio y cierre de sesión
+type AuthController struct {
+	Usuarios repository.UsuarioRepo
+	Signer   *auth.Signer
+}
+
+// NewAuthController crea una nueva instancia del controlador de autenticación
+func NewAuthController(usuarios repository.UsuarioRepo, signer *auth.Signer) *AuthController {
+	return &AuthController{Usuarios: usuarios, Signer: signer}
+}
+
+// sesionResponse es la respuesta de un inicio de sesión exitoso
+type sesionResponse struct {
+	Token   string         `json:"token"`
+	Expira  time.Time      `json:"expira"`
+	Usuario auth.Principal `json:"usuario"`
+}
+
+// Login valida las credenciales y emite un token de sesión
+func (c *AuthController) Login(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Usuario  string `json:"usuario" validar:"requerido,max=50"`
+		Password string `json:"password" validar:"requerido,max=128"`
+	}
+
+	if !leerCuerpo(w, r, &input) {
+		return
+	}
+
+	u, err := c.Usuarios.GetByUsuario(r.Context(), input.Usuario)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		log.Printf("Error al consultar usuario: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al iniciar sesión")
+		return
+	}
+	if err != nil {
+		// Se calcula un hash igual que con un usuario existente para no revelar cuáles existen
+		auth.CheckPasswordFicticia(input.Password)
+		responderError(w, r, http.StatusUnauthorized, models.CodCredencialesInvalidas, "Usuario o contraseña incorrectos")
+		return
+	}
+	if !auth.CheckPassword(u.PasswordHash, input.Password) {
+		responderError(w, r, http.StatusUnauthorized, models.CodCredencialesInvalidas, "Usuario o contraseña incorrectos")
+		return
+	}
+
+	token, hash, err := c.Signer.NewToken()
+	if err != nil {
+		log.Printf("Error al generar token de sesión: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al iniciar sesión")
+		return
+	}
+
+	sesion := models.Sesion{
+		TokenHash: hash,
+		IDUsuario: u.IDUsuario,
+		Expira:    time.Now().Add(c.Signer.Duracion),
+	}
+	if err := c.Usuarios.CreateSesion(r.Context(), sesion); err != nil {
+		log.Printf("Error al guardar sesión: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al iniciar sesión")
+		return
+	}
+
+	// Cada inicio de sesión elimina las sesiones vencidas; un fallo no impide el acceso
+	if err := c.Usuarios.DeleteSesionesVencidas(r.Context()); err != nil {
+		log.Printf("Error al eliminar sesiones vencidas: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sesionResponse{
+		Token:  token,
+		Expira: sesion.Expira,
+		Usuario: auth.Principal{
+			IDUsuario:    u.IDUsuario,
+			Usuario:      u.Usuario,
+			Rol:          u.Rol,
+			IDReferencia: u.IDReferencia,
+		},
+	})
+}
+
+// Logout invalida la sesión con la que se autenticó la solicitud
+func (c *AuthController) Logout(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.PrincipalFromContext(r.Context())
+
+	if err := c.Usuarios.DeleteSesion(r.Context(), p.Sesion); err != nil {
+		log.Printf("Error al eliminar sesión: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al cerrar sesión")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Me devuelve el usuario autenticado
+func (c *AuthController) Me(w http.ResponseWriter, r *http.Request) {
+	p, _ := auth.PrincipalFromContext(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"server_estudiantes/auth"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
+	"strings"
+	"testing"
+	"time"
+)
+
+// usuariosEnMemoria implementa repository.UsuarioRepo sobre mapas para probar los
+// controladores sin base de datos
+type usuariosEnMemoria struct {
+	usuarios map[string]models.Usuario
+	sesiones map[string]models.Sesion
+	// fallo, si no es nil, es el error que devuelve GetByUsuario
+	fallo error
+}
+
+var _ repository.UsuarioRepo = (*usuariosEnMemoria)(nil)
+
+func (f *usuariosEnMemoria) GetByUsuario(ctx context.Context, usuario string) (models.Usuario, error) {
+	if f.fallo != nil {
+		return models.Usuario{}, f.fallo
+	}
+	u, ok := f.usuarios[usuario]
+	if !ok {
+		return models.Usuario{}, &repository.NotFoundError{Entidad: repository.EntidadUsuario, ID: usuario}
+	}
+	return u, nil
+}
+
+func (f *usuariosEnMemoria) Create(ctx context.Context, u *models.Usuario) error {
+	if _, ok := f.usuarios[u.Usuario]; ok {
+		return repository.ErrDuplicate
+	}
+	f.usuarios[u.Usuario] = *u
+	return nil
+}
+
+func (f *usuariosEnMemoria) CreateSesion(ctx context.Context, s models.Sesion) error {
+	f.sesiones[s.TokenHash] = s
+	return nil
+}
+
+func (f *usuariosEnMemoria) GetSesion(ctx context.Context, tokenHash string) (models.Usuario, error) {
+	s, ok := f.sesiones[tokenHash]
+	if !ok || !s.Expira.After(time.Now()) {
+		return models.Usuario{}, &repository.NotFoundError{Entidad: repository.EntidadSesion}
+	}
+	for _, u := range f.usuarios {
+		if u.IDUsuario == s.IDUsuario {
+			return u, nil
+		}
+	}
+	return models.Usuario{}, &repository.NotFoundError{Entidad: repository.EntidadUsuario}
+}
+
+func (f *usuariosEnMemoria) DeleteSesion(ctx context.Context, tokenHash string) error {
+	delete(f.sesiones, tokenHash)
+	return nil
+}
+
+func (f *usuariosEnMemoria) DeleteSesionesVencidas(ctx context.Context) error {
+	for hash, s := range f.sesiones {
+		if !s.Expira.After(time.Now()) {
+			delete(f.sesiones, hash)
+		}
+	}
+	return nil
+}
+
+func TestLogin(t *testing.T) {
+	hash, err := auth.HashPassword("clave-correcta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	admin := models.Usuario{IDUsuario: "0123456789abcdef0123", Usuario: "admin", PasswordHash: hash, Rol: "admin"}
+	signer := auth.NewSigner([]byte(strings.Repeat("s", auth.MinSecretLen)), time.Hour)
+
+	casos := []struct {
+		nombre string
+		cuerpo string
+		fallo  error
+		status int
+		codigo string
+	}{
+		{"credenciales válidas", `{"usuario":"admin","password":"clave-correcta"}`, nil, http.StatusOK, ""},
+		{"contraseña incorrecta", `{"usuario":"admin","password":"otra"}`, nil, http.StatusUnauthorized, models.CodCredencialesInvalidas},
+		{"usuario inexistente", `{"usuario":"nadie","password":"clave-correcta"}`, nil, http.StatusUnauthorized, models.CodCredencialesInvalidas},
+		{"cuerpo sin contraseña", `{"usuario":"admin"}`, nil, http.StatusBadRequest, models.CodValidacion},
+		{"error del repositorio", `{"usuario":"admin","password":"clave-correcta"}`, errors.New("sin conexión"), http.StatusInternalServerError, models.CodErrorInterno},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			repo := &usuariosEnMemoria{
+				usuarios: map[string]models.Usuario{admin.Usuario: admin},
+				sesiones: map[string]models.Sesion{
+					"vencida": {TokenHash: "vencida", IDUsuario: admin.IDUsuario, Expira: time.Now().Add(-time.Minute)},
+				},
+				fallo: c.fallo,
+			}
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/auth/login", strings.NewReader(c.cuerpo))
+
+			NewAuthController(repo, signer).Login(w, r)
+
+			if w.Code != c.status {
+				t.Fatalf("status = %d, se esperaba %d: %s", w.Code, c.status, w.Body)
+			}
+			if c.status != http.StatusOK {
+				var e models.ErrorAPI
+				if err := json.NewDecoder(w.Body).Decode(&e); err != nil || e.Code != c.codigo {
+					t.Errorf("código = %q (%v), se esperaba %q", e.Code, err, c.codigo)
+				}
+				return
+			}
+
+			var s sesionResponse
+			if err := json.NewDecoder(w.Body).Decode(&s); err != nil {
+				t.Fatal(err)
+			}
+			if s.Usuario.IDUsuario != admin.IDUsuario {
+				t.Errorf("usuario = %+v, se esperaba %s", s.Usuario, admin.IDUsuario)
+			}
+			// La sesión emitida queda registrada y la vencida se elimina
+			if _, err := repo.GetSesion(r.Context(), auth.HashToken(s.Token)); err != nil {
+				t.Errorf("la sesión emitida no quedó registrada: %v", err)
+			}
+			if _, ok := repo.sesiones["vencida"]; ok {
+				t.Error("el inicio de sesión no eliminó la sesión vencida")
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package controllers
+
+import (
+	"net/http"
+	"server_estudiantes/auth"
+)
+
+const msgSinPermiso = "No tiene permiso para realizar esta acción"
+
+// esEstudianteAjeno indica si quien llama es un estudiante distinto del indicado
+func esEstudianteAjeno(r *http.Request, idEstudiante string) bool {
+	p, _ := auth.PrincipalFromContext(r.Context())
+	return p.Rol == auth.RolEstudiante && p.IDReferencia != idEstudiante
+}
+
+// esProfesorAjeno indica si quien llama es un profesor distinto del titular de la asignación
+func esProfesorAjeno(r *http.Request, idProfesor string) bool {
+	p, _ := auth.PrincipalFromContext(r.Context())
+	return p.Rol == auth.RolProfesor && p.IDReferencia != idProfesor
+}
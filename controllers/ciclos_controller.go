@@ -1,45 +1,36 @@
 package controllers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"server_estudiantes/models"
+	"server_estudiantes/repository"
+	"server_estudiantes/validacion"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
 // CiclosController maneja las solicitudes relacionadas con ciclos
 type CiclosController struct {
-	DB *sql.DB
+	Repo repository.CicloRepo
 }
 
 // NewCiclosController crea una nueva instancia del controlador de ciclos
-func NewCiclosController(db *sql.DB) *CiclosController {
-	return &CiclosController{DB: db}
+func NewCiclosController(repo repository.CicloRepo) *CiclosController {
+	return &CiclosController{Repo: repo}
 }
 
 // GetAllCiclos obtiene todos los ciclos
 func (c *CiclosController) GetAllCiclos(w http.ResponseWriter, r *http.Request) {
-	rows, err := c.DB.Query("SELECT id_, id_ciclos, ciclo, version FROM ciclos")
+	ciclos, err := c.Repo.List(r.Context())
 	if err != nil {
 		log.Printf("Error al consultar ciclos: %v", err)
-		http.Error(w, "Error al obtener ciclos", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener ciclos")
 		return
 	}
-	defer rows.Close()
-
-	ciclos := []models.Ciclo{}
-	for rows.Next() {
-		var c models.Ciclo
-		if err := rows.Scan(&c.ID, &c.IDCiclo, &c.Ciclo, &c.Version); err != nil {
-			log.Printf("Error al escanear ciclo: %v", err)
-			http.Error(w, "Error al procesar datos de ciclos", http.StatusInternalServerError)
-			return
-		}
-		ciclos = append(ciclos, c)
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ciclos)
@@ -50,19 +41,141 @@ func (c *CiclosController) GetCiclo(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var ciclo models.Ciclo
-	err := c.DB.QueryRow("SELECT id_, id_ciclos, ciclo, version FROM ciclos WHERE id_ciclos = ?", id).
-		Scan(&ciclo.ID, &ciclo.IDCiclo, &ciclo.Ciclo, &ciclo.Version)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Ciclo no encontrado", http.StatusNotFound)
+	ciclo, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodCicloNoEncontrado, "Ciclo no encontrado")
 		return
 	} else if err != nil {
 		log.Printf("Error al consultar ciclo: %v", err)
-		http.Error(w, "Error al obtener ciclo", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener ciclo")
 		return
 	}
 
+	setETag(w, ciclo.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(ciclo)
 }
+
+// cicloInput representa los datos enviados para crear o actualizar un ciclo
+type cicloInput struct {
+	Ciclo           string     `json:"ciclo" validar:"requerido,max=50"`
+	InicioMatricula *time.Time `json:"inicio_matricula"`
+	FinMatricula    *time.Time `json:"fin_matricula"`
+}
+
+// validarPeriodo verifica que el período de matrícula termine después de comenzar
+func (in cicloInput) validarPeriodo() []models.ErrorCampo {
+	if in.InicioMatricula != nil && in.FinMatricula != nil && !in.FinMatricula.After(*in.InicioMatricula) {
+		return []models.ErrorCampo{
+			validacion.Campo("fin_matricula", "periodo", "Debe ser posterior a inicio_matricula"),
+		}
+	}
+	return nil
+}
+
+// CreateCiclo crea un nuevo ciclo
+func (c *CiclosController) CreateCiclo(w http.ResponseWriter, r *http.Request) {
+	var input cicloInput
+	if !leerCuerpo(w, r, &input) || !responderValidacion(w, r, input.validarPeriodo()) {
+		return
+	}
+
+	nuevoCiclo := models.Ciclo{
+		Ciclo:           input.Ciclo,
+		InicioMatricula: input.InicioMatricula,
+		FinMatricula:    input.FinMatricula,
+	}
+	if err := c.Repo.Create(r.Context(), &nuevoCiclo); err != nil {
+		log.Printf("Error al insertar ciclo: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear ciclo")
+		return
+	}
+
+	setETag(w, nuevoCiclo.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(nuevoCiclo)
+}
+
+// UpdateCiclo actualiza un ciclo existente
+func (c *CiclosController) UpdateCiclo(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var input cicloInput
+	if !leerCuerpo(w, r, &input) || !responderValidacion(w, r, input.validarPeriodo()) {
+		return
+	}
+
+	// Verificar si el ciclo existe
+	ciclo, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodCicloNoEncontrado, "Ciclo no encontrado")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar ciclo: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar ciclo")
+		return
+	}
+
+	if !verificarIfMatch(w, r, ciclo.Version) {
+		return
+	}
+
+	// Actualizar ciclo solo si la versión no cambió
+	cicloActualizado := ciclo
+	cicloActualizado.Ciclo = input.Ciclo
+	cicloActualizado.InicioMatricula = input.InicioMatricula
+	cicloActualizado.FinMatricula = input.FinMatricula
+	err = c.Repo.Update(r.Context(), &cicloActualizado)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
+		log.Printf("Error al actualizar ciclo: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar ciclo")
+		return
+	}
+
+	setETag(w, cicloActualizado.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cicloActualizado)
+}
+
+// DeleteCiclo elimina un ciclo
+func (c *CiclosController) DeleteCiclo(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Verificar si el ciclo existe
+	ciclo, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodCicloNoEncontrado, "Ciclo no encontrado")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar ciclo: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar ciclo")
+		return
+	}
+
+	if !verificarIfMatch(w, r, ciclo.Version) {
+		return
+	}
+
+	// Eliminar ciclo si no tiene asignaciones
+	err = c.Repo.Delete(r.Context(), ciclo)
+	if errors.Is(err, repository.ErrInUse) {
+		responderError(w, r, http.StatusBadRequest, models.CodCicloEnUso, "No se puede eliminar el ciclo porque tiene asignaciones")
+		return
+	} else if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
+		log.Printf("Error al eliminar ciclo: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar ciclo")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Ciclo eliminado correctamente"})
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"server_estudiantes/middleware"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
+)
+
+// noEncontrado es el código y el mensaje que se devuelven cuando falta una entidad
+type noEncontrado struct {
+	codigo  string
+	mensaje string
+}
+
+// erroresNoEncontrado asocia cada entidad con el error que se devuelve al cliente
+var erroresNoEncontrado = map[string]noEncontrado{
+	repository.EntidadEstudiante:    {models.CodEstudianteNoEncontrado, "Estudiante no encontrado"},
+	repository.EntidadProfesor:      {models.CodProfesorNoEncontrado, "Profesor no encontrado"},
+	repository.EntidadAsignatura:    {models.CodAsignaturaNoEncontrada, "Asignatura no encontrada"},
+	repository.EntidadCiclo:         {models.CodCicloNoEncontrado, "Ciclo no encontrado"},
+	repository.EntidadAsignacion:    {models.CodAsignacionNoEncontrada, "Asignación no encontrada"},
+	repository.EntidadMatricula:     {models.CodMatriculaNoEncontrada, "Matrícula no encontrada"},
+	repository.EntidadNota:          {models.CodNotaNoEncontrada, "Registro de notas no encontrado"},
+	repository.EntidadUsuario:       {models.CodUsuarioNoEncontrado, "Usuario no encontrado"},
+	repository.EntidadPrerrequisito: {models.CodPrerrequisitoNoEncontrado, "Prerrequisito no encontrado"},
+}
+
+// responderError responde con el sobre de error JSON de la API
+func responderError(w http.ResponseWriter, r *http.Request, status int, codigo, mensaje string) {
+	middleware.WriteError(w, r, status, codigo, mensaje, nil)
+}
+
+// responderErrorDetalles responde con el sobre de error JSON incluyendo detalles
+func responderErrorDetalles(w http.ResponseWriter, r *http.Request, status int, codigo, mensaje string, detalles interface{}) {
+	middleware.WriteError(w, r, status, codigo, mensaje, detalles)
+}
+
+// responderNoEncontrado responde 404 con el código de la entidad de un error repository.ErrNotFound
+func responderNoEncontrado(w http.ResponseWriter, r *http.Request, err error) {
+	var nf *repository.NotFoundError
+	if errors.As(err, &nf) {
+		if e, ok := erroresNoEncontrado[nf.Entidad]; ok {
+			responderErrorDetalles(w, r, http.StatusNotFound, e.codigo, e.mensaje, map[string]string{"id": nf.ID})
+			return
+		}
+	}
+	responderError(w, r, http.StatusNotFound, models.CodNoEncontrado, "Registro no encontrado")
+}
@@ -1,50 +1,53 @@
 package controllers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"server_estudiantes/config"
-	"server_estudiantes/middleware"
+	"server_estudiantes/auth"
 	"server_estudiantes/models"
+	"server_estudiantes/repository"
 
 	"github.com/gorilla/mux"
 )
 
 // EstudiantesController maneja las solicitudes relacionadas con estudiantes
 type EstudiantesController struct {
-	DB *sql.DB
+	Repo repository.EstudianteRepo
+	Hub  *Hub
 }
 
 // NewEstudiantesController crea una nueva instancia del controlador de estudiantes
-func NewEstudiantesController(db *sql.DB) *EstudiantesController {
-	return &EstudiantesController{DB: db}
+func NewEstudiantesController(repo repository.EstudianteRepo, hub *Hub) *EstudiantesController {
+	return &EstudiantesController{Repo: repo, Hub: hub}
 }
 
-// GetAllEstudiantes obtiene todos los estudiantes
+// publicar notifica el cambio a los suscriptores del estudiante y de la tabla
+func (c *EstudiantesController) publicar(operacion string, e models.Estudiante) {
+	c.Hub.Publicar(operacion, repository.TablaEstudiantes, e,
+		TemaDeEstudiante(e.IDEstudiante), TemaDeTabla(repository.TablaEstudiantes))
+}
+
+// GetAllEstudiantes obtiene una página de estudiantes filtrada y ordenada
 func (c *EstudiantesController) GetAllEstudiantes(w http.ResponseWriter, r *http.Request) {
-	rows, err := c.DB.Query("SELECT id_, id_estudiantes, nombre, version FROM estudiantes")
+	p, err := leerListParams(r)
 	if err != nil {
-		log.Printf("Error al consultar estudiantes: %v", err)
-		http.Error(w, "Error al obtener estudiantes", http.StatusInternalServerError)
+		responderError(w, r, http.StatusBadRequest, models.CodParametroInvalido, err.Error())
 		return
 	}
-	defer rows.Close()
-
-	estudiantes := []models.Estudiante{}
-	for rows.Next() {
-		var e models.Estudiante
-		if err := rows.Scan(&e.ID, &e.IDEstudiante, &e.Nombre, &e.Version); err != nil {
-			log.Printf("Error al escanear estudiante: %v", err)
-			http.Error(w, "Error al procesar datos de estudiantes", http.StatusInternalServerError)
-			return
-		}
-		estudiantes = append(estudiantes, e)
+
+	estudiantes, total, siguiente, err := c.Repo.List(r.Context(), p)
+	if errors.Is(err, repository.ErrInvalidParam) {
+		responderError(w, r, http.StatusBadRequest, models.CodParametroInvalido, err.Error())
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar estudiantes: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener estudiantes")
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(estudiantes)
+	responderPagina(w, estudiantes, total, siguiente, p)
 }
 
 // GetEstudiante obtiene un estudiante por su ID
@@ -52,75 +55,58 @@ func (c *EstudiantesController) GetEstudiante(w http.ResponseWriter, r *http.Req
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var e models.Estudiante
-	err := c.DB.QueryRow("SELECT id_, id_estudiantes, nombre, version FROM estudiantes WHERE id_estudiantes = ?", id).
-		Scan(&e.ID, &e.IDEstudiante, &e.Nombre, &e.Version)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Estudiante no encontrado", http.StatusNotFound)
+	e, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodEstudianteNoEncontrado, "Estudiante no encontrado")
 		return
 	} else if err != nil {
 		log.Printf("Error al consultar estudiante: %v", err)
-		http.Error(w, "Error al obtener estudiante", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener estudiante")
 		return
 	}
 
+	setETag(w, e.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(e)
 }
 
-// CreateEstudiante crea un nuevo estudiante
+// CreateEstudiante crea un nuevo estudiante; si se indica una contraseña también crea sus
+// credenciales. Solo secretaría y administración registran estudiantes.
 func (c *EstudiantesController) CreateEstudiante(w http.ResponseWriter, r *http.Request) {
 	var input struct {
-		Nombre string `json:"nombre"`
+		Nombre   string `json:"nombre" validar:"requerido,max=100"`
+		Password string `json:"password" validar:"min=8,max=128"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Datos inválidos", http.StatusBadRequest)
-		return
-	}
-
-	if input.Nombre == "" {
-		http.Error(w, "El nombre es requerido", http.StatusBadRequest)
-		return
-	}
-
-	id, err := config.GenerateID()
-	if err != nil {
-		log.Printf("Error al generar ID: %v", err)
-		http.Error(w, "Error al crear estudiante", http.StatusInternalServerError)
-		return
-	}
-
-	idEstudiante, err := config.GenerateID()
-	if err != nil {
-		log.Printf("Error al generar ID de estudiante: %v", err)
-		http.Error(w, "Error al crear estudiante", http.StatusInternalServerError)
-		return
-	}
-
-	_, err = c.DB.Exec(
-		"INSERT INTO estudiantes (id_, id_estudiantes, nombre, version) VALUES (?, ?, ?, ?)",
-		id, idEstudiante, input.Nombre, 1,
-	)
-	if err != nil {
-		log.Printf("Error al insertar estudiante: %v", err)
-		http.Error(w, "Error al crear estudiante", http.StatusInternalServerError)
+	if !leerCuerpo(w, r, &input) {
 		return
 	}
 
-	nuevoEstudiante := models.Estudiante{
-		ID:          id,
-		IDEstudiante: idEstudiante,
-		Nombre:      input.Nombre,
-		Version:     1,
+	nuevoEstudiante := models.Estudiante{Nombre: input.Nombre}
+	if input.Password == "" {
+		if err := c.Repo.Create(r.Context(), &nuevoEstudiante); err != nil {
+			log.Printf("Error al insertar estudiante: %v", err)
+			responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear estudiante")
+			return
+		}
+	} else {
+		// Registro con credenciales: el estudiante inicia sesión con su ID
+		hash, err := auth.HashPassword(input.Password)
+		if err != nil {
+			log.Printf("Error al generar hash de contraseña: %v", err)
+			responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear estudiante")
+			return
+		}
+		if _, err := c.Repo.Registrar(r.Context(), &nuevoEstudiante, hash); err != nil {
+			log.Printf("Error al registrar estudiante: %v", err)
+			responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear estudiante")
+			return
+		}
 	}
 
-	// Notificar al middleware
-	if err := middleware.SendToMiddleware("CREATE", "estudiantes", nuevoEstudiante); err != nil {
-		log.Printf("Error al notificar al middleware: %v", err)
-	}
+	c.publicar(models.OperacionCrear, nuevoEstudiante)
 
+	setETag(w, nuevoEstudiante.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(nuevoEstudiante)
@@ -132,57 +118,44 @@ func (c *EstudiantesController) UpdateEstudiante(w http.ResponseWriter, r *http.
 	id := vars["id"]
 
 	var input struct {
-		Nombre string `json:"nombre"`
+		Nombre string `json:"nombre" validar:"requerido,max=100"`
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Datos inválidos", http.StatusBadRequest)
-		return
-	}
-
-	if input.Nombre == "" {
-		http.Error(w, "El nombre es requerido", http.StatusBadRequest)
+	if !leerCuerpo(w, r, &input) {
 		return
 	}
 
 	// Verificar si el estudiante existe
-	var estudiante models.Estudiante
-	err := c.DB.QueryRow("SELECT id_, id_estudiantes, nombre, version FROM estudiantes WHERE id_estudiantes = ?", id).
-		Scan(&estudiante.ID, &estudiante.IDEstudiante, &estudiante.Nombre, &estudiante.Version)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Estudiante no encontrado", http.StatusNotFound)
+	estudiante, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodEstudianteNoEncontrado, "Estudiante no encontrado")
 		return
 	} else if err != nil {
 		log.Printf("Error al consultar estudiante: %v", err)
-		http.Error(w, "Error al actualizar estudiante", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar estudiante")
 		return
 	}
 
-	// Actualizar estudiante
-	nuevaVersion := estudiante.Version + 1
-	_, err = c.DB.Exec(
-		"UPDATE estudiantes SET nombre = ?, version = ? WHERE id_estudiantes = ?",
-		input.Nombre, nuevaVersion, id,
-	)
-	if err != nil {
-		log.Printf("Error al actualizar estudiante: %v", err)
-		http.Error(w, "Error al actualizar estudiante", http.StatusInternalServerError)
+	if !verificarIfMatch(w, r, estudiante.Version) {
 		return
 	}
 
-	estudianteActualizado := models.Estudiante{
-		ID:          estudiante.ID,
-		IDEstudiante: estudiante.IDEstudiante,
-		Nombre:      input.Nombre,
-		Version:     nuevaVersion,
+	// Actualizar estudiante solo si la versión no cambió
+	estudianteActualizado := estudiante
+	estudianteActualizado.Nombre = input.Nombre
+	err = c.Repo.Update(r.Context(), &estudianteActualizado)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
+		log.Printf("Error al actualizar estudiante: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar estudiante")
+		return
 	}
 
-	// Notificar al middleware
-	if err := middleware.SendToMiddleware("UPDATE", "estudiantes", estudianteActualizado); err != nil {
-		log.Printf("Error al notificar al middleware: %v", err)
-	}
+	c.publicar(models.OperacionActualizar, estudianteActualizado)
 
+	setETag(w, estudianteActualizado.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(estudianteActualizado)
 }
@@ -193,45 +166,35 @@ func (c *EstudiantesController) DeleteEstudiante(w http.ResponseWriter, r *http.
 	id := vars["id"]
 
 	// Verificar si el estudiante existe
-	var estudiante models.Estudiante
-	err := c.DB.QueryRow("SELECT id_, id_estudiantes, nombre, version FROM estudiantes WHERE id_estudiantes = ?", id).
-		Scan(&estudiante.ID, &estudiante.IDEstudiante, &estudiante.Nombre, &estudiante.Version)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Estudiante no encontrado", http.StatusNotFound)
+	estudiante, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodEstudianteNoEncontrado, "Estudiante no encontrado")
 		return
 	} else if err != nil {
 		log.Printf("Error al consultar estudiante: %v", err)
-		http.Error(w, "Error al eliminar estudiante", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar estudiante")
 		return
 	}
 
-	// Verificar si el estudiante tiene matrículas
-	var count int
-	err = c.DB.QueryRow("SELECT COUNT(*) FROM matriculas WHERE id_estudiantes = ?", id).Scan(&count)
-	if err != nil {
-		log.Printf("Error al verificar matrículas: %v", err)
-		http.Error(w, "Error al eliminar estudiante", http.StatusInternalServerError)
+	if !verificarIfMatch(w, r, estudiante.Version) {
 		return
 	}
 
-	if count > 0 {
-		http.Error(w, "No se puede eliminar el estudiante porque tiene matrículas", http.StatusBadRequest)
+	// Eliminar estudiante si no tiene matrículas
+	err = c.Repo.Delete(r.Context(), estudiante)
+	if errors.Is(err, repository.ErrInUse) {
+		responderError(w, r, http.StatusBadRequest, models.CodEstudianteConMatriculas, "No se puede eliminar el estudiante porque tiene matrículas")
 		return
-	}
-
-	// Eliminar estudiante
-	_, err = c.DB.Exec("DELETE FROM estudiantes WHERE id_estudiantes = ?", id)
-	if err != nil {
+	} else if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
 		log.Printf("Error al eliminar estudiante: %v", err)
-		http.Error(w, "Error al eliminar estudiante", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar estudiante")
 		return
 	}
 
-	// Notificar al middleware
-	if err := middleware.SendToMiddleware("DELETE", "estudiantes", estudiante); err != nil {
-		log.Printf("Error al notificar al middleware: %v", err)
-	}
+	c.publicar(models.OperacionEliminar, estudiante)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"message": "Estudiante eliminado correctamente"})
@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"server_estudiantes/models"
+	"strings"
+)
+
+// Mensaje devuelto cuando la versión enviada por el cliente ya no es la actual
+const msgVersionNoCoincide = "El recurso fue modificado por otro usuario"
+
+// etagVersion genera la ETag de un recurso a partir de su versión
+func etagVersion(version int) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// setETag agrega la cabecera ETag correspondiente a la versión del recurso
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", etagVersion(version))
+}
+
+// ifMatch indica si la cabecera If-Match coincide con la versión actual del recurso.
+// Una solicitud sin If-Match se acepta para mantener la compatibilidad con los clientes existentes.
+func ifMatch(r *http.Request, version int) bool {
+	header := r.Header.Get("If-Match")
+	if header == "" {
+		return true
+	}
+
+	actual := etagVersion(version)
+	for _, etag := range strings.Split(header, ",") {
+		etag = strings.TrimPrefix(strings.TrimSpace(etag), "W/")
+		if etag == "*" || etag == actual {
+			return true
+		}
+	}
+	return false
+}
+
+// verificarIfMatch responde 412 cuando la cabecera If-Match no coincide con la versión actual
+func verificarIfMatch(w http.ResponseWriter, r *http.Request, version int) bool {
+	if !ifMatch(r, version) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"server_estudiantes/config"
+	"server_estudiantes/kardex"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Formatos en los que se entrega el kardex
+const (
+	formatoJSON = "json"
+	formatoCSV  = "csv"
+	formatoPDF  = "pdf"
+)
+
+// KardexController genera el historial académico de los estudiantes
+type KardexController struct {
+	Notas       repository.NotaRepo
+	Estudiantes repository.EstudianteRepo
+	Politica    config.PoliticaCalificacion
+}
+
+// NewKardexController crea una nueva instancia del controlador de kardex
+func NewKardexController(notas repository.NotaRepo, estudiantes repository.EstudianteRepo, politica config.PoliticaCalificacion) *KardexController {
+	return &KardexController{Notas: notas, Estudiantes: estudiantes, Politica: politica}
+}
+
+// GetKardex obtiene el kardex de un estudiante agrupado por ciclo; el parámetro
+// formato elige entre json (por defecto), csv y pdf
+func (c *KardexController) GetKardex(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idEstudiante := vars["id"]
+
+	formato := r.URL.Query().Get("formato")
+	if formato == "" {
+		formato = formatoJSON
+	}
+	if formato != formatoJSON && formato != formatoCSV && formato != formatoPDF {
+		responderError(w, r, http.StatusBadRequest, models.CodParametroInvalido, "formato debe ser json, csv o pdf")
+		return
+	}
+
+	estudiante, err := c.Estudiantes.Get(r.Context(), idEstudiante)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodEstudianteNoEncontrado, "Estudiante no encontrado")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar estudiante: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al generar el kardex")
+		return
+	}
+
+	notas, err := c.Notas.ListByEstudiante(r.Context(), idEstudiante)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodEstudianteNoEncontrado, "Estudiante no encontrado")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar notas del estudiante: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al generar el kardex")
+		return
+	}
+
+	k := kardex.Construir(estudiante, notas, c.Politica, time.Now())
+
+	switch formato {
+	case formatoCSV:
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", disposicion("attachment", "kardex-"+estudiante.IDEstudiante+".csv"))
+		err = kardex.EscribirCSV(w, k)
+	case formatoPDF:
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", disposicion("inline", "kardex-"+estudiante.IDEstudiante+".pdf"))
+		err = kardex.EscribirPDF(w, k)
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(k)
+	}
+	if err != nil {
+		log.Printf("Error al escribir el kardex: %v", err)
+	}
+}
+
+// disposicion arma la cabecera Content-Disposition escapando el nombre del archivo
+func disposicion(tipo, archivo string) string {
+	return mime.FormatMediaType(tipo, map[string]string{"filename": archivo})
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
+	"strconv"
+	"strings"
+)
+
+// Límites de registros por página
+const (
+	limiteDefecto = 50
+	limiteMaximo  = 200
+)
+
+// leerListParams obtiene la paginación, el orden y los filtros de la query string.
+// Todo parámetro distinto de limit, cursor y sort se interpreta como filtro.
+func leerListParams(r *http.Request) (repository.ListParams, error) {
+	query := r.URL.Query()
+	p := repository.ListParams{Limit: limiteDefecto, Filtros: map[string]string{}}
+
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 {
+			return p, fmt.Errorf("El límite debe ser un número positivo")
+		}
+		p.Limit = min(limit, limiteMaximo)
+	}
+
+	if raw := query.Get("sort"); raw != "" {
+		for _, campo := range strings.Split(raw, ",") {
+			campo = strings.TrimSpace(campo)
+			if campo == "" {
+				continue
+			}
+			p.Orden = append(p.Orden, repository.Orden{
+				Campo: strings.TrimPrefix(campo, "-"),
+				Desc:  strings.HasPrefix(campo, "-"),
+			})
+		}
+	}
+
+	// El cursor solo es válido con el mismo orden con el que se generó
+	if raw := query.Get("cursor"); raw != "" {
+		despues, err := decodeCursor(raw, p.Orden)
+		if err != nil {
+			return p, fmt.Errorf("Cursor inválido")
+		}
+		p.Despues = despues
+	}
+
+	for campo := range query {
+		if campo == "limit" || campo == "cursor" || campo == "sort" {
+			continue
+		}
+		p.Filtros[campo] = query.Get(campo)
+	}
+
+	return p, nil
+}
+
+// cursor es el contenido de un cursor de listado: el orden con el que se generó y los
+// valores de orden y de desempate del último registro entregado
+type cursor struct {
+	Orden   string   `json:"o"`
+	Valores []string `json:"v"`
+}
+
+// firmaOrden representa un orden igual que el parámetro sort
+func firmaOrden(orden []repository.Orden) string {
+	campos := make([]string, len(orden))
+	for i, o := range orden {
+		campos[i] = o.Campo
+		if o.Desc {
+			campos[i] = "-" + o.Campo
+		}
+	}
+	return strings.Join(campos, ",")
+}
+
+// encodeCursor genera un cursor opaco con la posición del último registro de la página
+func encodeCursor(orden []repository.Orden, valores []string) string {
+	raw, _ := json.Marshal(cursor{Orden: firmaOrden(orden), Valores: valores})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor obtiene la posición codificada en un cursor y verifica que corresponda al orden
+func decodeCursor(raw string, orden []repository.Orden) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	// Los valores son los del orden más el desempate
+	if c.Orden != firmaOrden(orden) || len(c.Valores) != len(orden)+1 {
+		return nil, fmt.Errorf("cursor inválido")
+	}
+	return c.Valores, nil
+}
+
+// responderPagina escribe el sobre de respuesta de un listado paginado
+func responderPagina[T any](w http.ResponseWriter, data []T, total int, siguiente []string, p repository.ListParams) {
+	pagina := models.Pagina[T]{Data: data, Total: total, Limit: p.Limit}
+	if siguiente != nil {
+		pagina.NextCursor = encodeCursor(p.Orden, siguiente)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pagina)
+}
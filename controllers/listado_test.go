@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"server_estudiantes/repository"
+	"testing"
+)
+
+func TestLeerListParams(t *testing.T) {
+	porNombre := []repository.Orden{{Campo: "nombre"}}
+	cursorNombre := encodeCursor(porNombre, []string{"Ana", "0123456789abcdef0123"})
+
+	casos := []struct {
+		nombre   string
+		query    url.Values
+		esperado repository.ListParams
+		invalido bool
+	}{
+		{
+			nombre:   "valores por defecto",
+			esperado: repository.ListParams{Limit: limiteDefecto, Filtros: map[string]string{}},
+		},
+		{
+			nombre:   "límite acotado al máximo",
+			query:    url.Values{"limit": {"500"}},
+			esperado: repository.ListParams{Limit: limiteMaximo, Filtros: map[string]string{}},
+		},
+		{nombre: "límite cero", query: url.Values{"limit": {"0"}}, invalido: true},
+		{nombre: "límite no numérico", query: url.Values{"limit": {"diez"}}, invalido: true},
+		{
+			nombre: "orden y filtros",
+			query:  url.Values{"sort": {"nombre,-version"}, "nombre": {"Ana"}},
+			esperado: repository.ListParams{
+				Limit:   limiteDefecto,
+				Orden:   []repository.Orden{{Campo: "nombre"}, {Campo: "version", Desc: true}},
+				Filtros: map[string]string{"nombre": "Ana"},
+			},
+		},
+		{
+			nombre: "cursor del mismo orden",
+			query:  url.Values{"sort": {"nombre"}, "cursor": {cursorNombre}},
+			esperado: repository.ListParams{
+				Limit:   limiteDefecto,
+				Despues: []string{"Ana", "0123456789abcdef0123"},
+				Orden:   porNombre,
+				Filtros: map[string]string{},
+			},
+		},
+		{nombre: "cursor de otro orden", query: url.Values{"sort": {"-nombre"}, "cursor": {cursorNombre}}, invalido: true},
+		{nombre: "cursor sin el orden con que se generó", query: url.Values{"cursor": {cursorNombre}}, invalido: true},
+		{nombre: "cursor que no es base64", query: url.Values{"cursor": {"%%%"}}, invalido: true},
+		{
+			nombre:   "cursor con contenido inválido",
+			query:    url.Values{"cursor": {base64.RawURLEncoding.EncodeToString([]byte("o:50"))}},
+			invalido: true,
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/estudiantes?"+c.query.Encode(), nil)
+			p, err := leerListParams(r)
+			if c.invalido {
+				if err == nil {
+					t.Fatalf("se esperaba un error, se obtuvo %+v", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if !reflect.DeepEqual(p, c.esperado) {
+				t.Errorf("leerListParams() = %+v, se esperaba %+v", p, c.esperado)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	orden := []repository.Orden{{Campo: "ciclo", Desc: true}, {Campo: "nombre_asignatura"}}
+	valores := []string{"2024-1", "Cálculo", "0123456789abcdef0123"}
+
+	casos := []struct {
+		nombre   string
+		cursor   string
+		orden    []repository.Orden
+		esperado []string
+	}{
+		{"ida y vuelta", encodeCursor(orden, valores), orden, valores},
+		{"sentido distinto", encodeCursor(orden, valores), []repository.Orden{{Campo: "ciclo"}, {Campo: "nombre_asignatura"}}, nil},
+		{"sin desempate", encodeCursor(orden, valores[:2]), orden, nil},
+		{"vacío", "", orden, nil},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			got, err := decodeCursor(c.cursor, c.orden)
+			if c.esperado == nil {
+				if err == nil {
+					t.Errorf("se esperaba un error, se obtuvo %v", got)
+				}
+				return
+			}
+			if err != nil || !reflect.DeepEqual(got, c.esperado) {
+				t.Errorf("decodeCursor() = %v, %v; se esperaba %v", got, err, c.esperado)
+			}
+		})
+	}
+}
@@ -1,77 +1,77 @@
 package controllers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
-	"server_estudiantes/config"
-	"server_estudiantes/middleware"
 	"server_estudiantes/models"
+	"server_estudiantes/repository"
 
 	"github.com/gorilla/mux"
 )
 
 // MatriculasController maneja las solicitudes relacionadas con matrículas
 type MatriculasController struct {
-	DB *sql.DB
+	Repo repository.MatriculaRepo
+	Hub  *Hub
 }
 
 // NewMatriculasController crea una nueva instancia del controlador de matrículas
-func NewMatriculasController(db *sql.DB) *MatriculasController {
-	return &MatriculasController{DB: db}
+func NewMatriculasController(repo repository.MatriculaRepo, hub *Hub) *MatriculasController {
+	return &MatriculasController{Repo: repo, Hub: hub}
 }
 
-// GetAllMatriculas obtiene todas las matrículas
+// publicar notifica un cambio de la tabla indicada a los suscriptores del estudiante,
+// de la asignación y de la tabla; temas agrega destinatarios adicionales
+func (c *MatriculasController) publicar(operacion, tabla string, data interface{}, m models.Matricula, temas ...string) {
+	temas = append(temas, TemaDeEstudiante(m.IDEstudiante), TemaDeAsignacion(m.IDAsignacion), TemaDeTabla(tabla))
+	c.Hub.Publicar(operacion, tabla, data, temas...)
+}
+
+// responderReglaMatricula responde 409 si err es el rechazo de una regla de matrícula
+// y devuelve false si se trata de otro error
+func responderReglaMatricula(w http.ResponseWriter, r *http.Request, err error) bool {
+	var prerrequisitos *repository.PrerrequisitosError
+	var creditos *repository.LimiteCreditosError
+	switch {
+	case errors.Is(err, repository.ErrMatriculaCerrada):
+		responderError(w, r, http.StatusConflict, models.CodMatriculaCerrada, "El ciclo no está en período de matrícula")
+	case errors.Is(err, repository.ErrAsignaturaRepetida):
+		responderError(w, r, http.StatusConflict, models.CodAsignaturaYaMatriculada, "El estudiante ya está matriculado en otra sección de esta asignatura en el ciclo")
+	case errors.Is(err, repository.ErrCupoAgotado):
+		responderError(w, r, http.StatusConflict, models.CodCupoAgotado, "La asignación no tiene cupos disponibles")
+	case errors.As(err, &creditos):
+		responderErrorDetalles(w, r, http.StatusConflict, models.CodLimiteCreditos,
+			"La matrícula supera el máximo de créditos por ciclo", creditos)
+	case errors.As(err, &prerrequisitos):
+		responderErrorDetalles(w, r, http.StatusConflict, models.CodPrerrequisitosPendientes,
+			"El estudiante no aprobó los prerrequisitos de la asignatura", prerrequisitos.Faltantes)
+	default:
+		return false
+	}
+	return true
+}
+
+// GetAllMatriculas obtiene una página de matrículas filtrada y ordenada
 func (c *MatriculasController) GetAllMatriculas(w http.ResponseWriter, r *http.Request) {
-	rows, err := c.DB.Query(`
-		SELECT 
-			m.id_, 
-			m.id_matriculas, 
-			m.id_estudiantes, 
-			m.id_profesores_ciclos_asignaturas, 
-			m.version,
-			e.nombre AS nombre_estudiante,
-			p.nombre AS nombre_profesor,
-			a.nombre_asignatura,
-			c.ciclo
-		FROM matriculas m
-		JOIN estudiantes e ON m.id_estudiantes = e.id_estudiantes
-		JOIN profesores_ciclos_asignaturas pca ON m.id_profesores_ciclos_asignaturas = pca.id_profesores_ciclos_asignaturas
-		JOIN profesores p ON pca.id_profesores = p.id_profesores
-		JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
-		JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
-	`)
+	p, err := leerListParams(r)
 	if err != nil {
-		log.Printf("Error al consultar matrículas: %v", err)
-		http.Error(w, "Error al obtener matrículas", http.StatusInternalServerError)
+		responderError(w, r, http.StatusBadRequest, models.CodParametroInvalido, err.Error())
 		return
 	}
-	defer rows.Close()
-
-	matriculas := []models.Matricula{}
-	for rows.Next() {
-		var m models.Matricula
-		if err := rows.Scan(
-			&m.ID, 
-			&m.IDMatricula, 
-			&m.IDEstudiante, 
-			&m.IDAsignacion, 
-			&m.Version,
-			&m.NombreEstudiante,
-			&m.NombreProfesor,
-			&m.NombreAsignatura,
-			&m.Ciclo,
-		); err != nil {
-			log.Printf("Error al escanear matrícula: %v", err)
-			http.Error(w, "Error al procesar datos de matrículas", http.StatusInternalServerError)
-			return
-		}
-		matriculas = append(matriculas, m)
+
+	matriculas, total, siguiente, err := c.Repo.List(r.Context(), p)
+	if errors.Is(err, repository.ErrInvalidParam) {
+		responderError(w, r, http.StatusBadRequest, models.CodParametroInvalido, err.Error())
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar matrículas: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener matrículas")
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(matriculas)
+	responderPagina(w, matriculas, total, siguiente, p)
 }
 
 // GetMatricula obtiene una matrícula por su ID
@@ -79,172 +79,105 @@ func (c *MatriculasController) GetMatricula(w http.ResponseWriter, r *http.Reque
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var m models.Matricula
-	err := c.DB.QueryRow(`
-		SELECT 
-			m.id_, 
-			m.id_matriculas, 
-			m.id_estudiantes, 
-			m.id_profesores_ciclos_asignaturas, 
-			m.version,
-			e.nombre AS nombre_estudiante,
-			p.nombre AS nombre_profesor,
-			a.nombre_asignatura,
-			c.ciclo
-		FROM matriculas m
-		JOIN estudiantes e ON m.id_estudiantes = e.id_estudiantes
-		JOIN profesores_ciclos_asignaturas pca ON m.id_profesores_ciclos_asignaturas = pca.id_profesores_ciclos_asignaturas
-		JOIN profesores p ON pca.id_profesores = p.id_profesores
-		JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
-		JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
-		WHERE m.id_matriculas = ?
-	`, id).Scan(
-		&m.ID, 
-		&m.IDMatricula, 
-		&m.IDEstudiante, 
-		&m.IDAsignacion, 
-		&m.Version,
-		&m.NombreEstudiante,
-		&m.NombreProfesor,
-		&m.NombreAsignatura,
-		&m.Ciclo,
-	)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Matrícula no encontrada", http.StatusNotFound)
+	m, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodMatriculaNoEncontrada, "Matrícula no encontrada")
 		return
 	} else if err != nil {
 		log.Printf("Error al consultar matrícula: %v", err)
-		http.Error(w, "Error al obtener matrícula", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener matrícula")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(m)
-}
-
-// CreateMatricula crea una nueva matrícula
-func (c *MatriculasController) CreateMatricula(w http.ResponseWriter, r *http.Request) {
-	var input struct {
-		IDEstudiante string `json:"id_estudiantes"`
-		IDAsignacion string `json:"id_profesores_ciclos_asignaturas"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Datos inválidos", http.StatusBadRequest)
+	if esEstudianteAjeno(r, m.IDEstudiante) {
+		responderError(w, r, http.StatusForbidden, models.CodSinPermiso, msgSinPermiso)
 		return
 	}
 
-	if input.IDEstudiante == "" || input.IDAsignacion == "" {
-		http.Error(w, "Todos los campos son requeridos", http.StatusBadRequest)
-		return
-	}
+	setETag(w, m.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(m)
+}
 
-	// Verificar si existe el estudiante
-	var estudiante models.Estudiante
-	err := c.DB.QueryRow("SELECT id_, id_estudiantes, nombre, version FROM estudiantes WHERE id_estudiantes = ?", input.IDEstudiante).
-		Scan(&estudiante.ID, &estudiante.IDEstudiante, &estudiante.Nombre, &estudiante.Version)
-	if err == sql.ErrNoRows {
-		http.Error(w, "Estudiante no encontrado", http.StatusNotFound)
-		return
-	} else if err != nil {
-		log.Printf("Error al verificar estudiante: %v", err)
-		http.Error(w, "Error al crear matrícula", http.StatusInternalServerError)
-		return
-	}
+// GetMatriculasByEstudiante obtiene las matrículas de un estudiante
+func (c *MatriculasController) GetMatriculasByEstudiante(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idEstudiante := vars["id"]
 
-	// Verificar si existe la asignación
-	var asignacion models.Asignacion
-	err = c.DB.QueryRow("SELECT id_, id_profesores_ciclos_asignaturas, id_profesores, id_asignaturas, id_ciclos, version FROM profesores_ciclos_asignaturas WHERE id_profesores_ciclos_asignaturas = ?", input.IDAsignacion).
-		Scan(&asignacion.ID, &asignacion.IDAsignacion, &asignacion.IDProfesor, &asignacion.IDAsignatura, &asignacion.IDCiclo, &asignacion.Version)
-	if err == sql.ErrNoRows {
-		http.Error(w, "Asignación no encontrada", http.StatusNotFound)
+	matriculas, err := c.Repo.ListByEstudiante(r.Context(), idEstudiante)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodEstudianteNoEncontrado, "Estudiante no encontrado")
 		return
 	} else if err != nil {
-		log.Printf("Error al verificar asignación: %v", err)
-		http.Error(w, "Error al crear matrícula", http.StatusInternalServerError)
+		log.Printf("Error al consultar matrículas del estudiante: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener matrículas del estudiante")
 		return
 	}
 
-	// Verificar si ya existe la matrícula
-	var count int
-	err = c.DB.QueryRow("SELECT COUNT(*) FROM matriculas WHERE id_estudiantes = ? AND id_profesores_ciclos_asignaturas = ?", input.IDEstudiante, input.IDAsignacion).Scan(&count)
-	if err != nil {
-		log.Printf("Error al verificar matrícula existente: %v", err)
-		http.Error(w, "Error al crear matrícula", http.StatusInternalServerError)
-		return
-	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matriculas)
+}
 
-	if count > 0 {
-		http.Error(w, "El estudiante ya está matriculado en esta asignatura", http.StatusBadRequest)
-		return
-	}
+// GetMatriculasByAsignacion obtiene la nómina de estudiantes matriculados en una asignación
+func (c *MatriculasController) GetMatriculasByAsignacion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idAsignacion := vars["id"]
 
-	// Crear matrícula
-	id, err := config.GenerateID()
-	if err != nil {
-		log.Printf("Error al generar ID: %v", err)
-		http.Error(w, "Error al crear matrícula", http.StatusInternalServerError)
+	matriculas, err := c.Repo.ListByAsignacion(r.Context(), idAsignacion)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodAsignacionNoEncontrada, "Asignación no encontrada")
 		return
-	}
-
-	idMatricula, err := config.GenerateID()
-	if err != nil {
-		log.Printf("Error al generar ID de matrícula: %v", err)
-		http.Error(w, "Error al crear matrícula", http.StatusInternalServerError)
+	} else if err != nil {
+		log.Printf("Error al consultar matrículas de la asignación: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener matrículas de la asignación")
 		return
 	}
 
-	_, err = c.DB.Exec(
-		"INSERT INTO matriculas (id_, id_matriculas, id_estudiantes, id_profesores_ciclos_asignaturas, version) VALUES (?, ?, ?, ?, ?)",
-		id, idMatricula, input.IDEstudiante, input.IDAsignacion, 1,
-	)
-	if err != nil {
-		log.Printf("Error al insertar matrícula: %v", err)
-		http.Error(w, "Error al crear matrícula", http.StatusInternalServerError)
-		return
-	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(matriculas)
+}
 
-	// Crear registro de notas
-	idRegistro, err := config.GenerateID()
-	if err != nil {
-		log.Printf("Error al generar ID de registro: %v", err)
-		http.Error(w, "Error al crear registro de notas", http.StatusInternalServerError)
-		return
+// CreateMatricula crea una nueva matrícula junto con su registro de notas en una sola transacción
+func (c *MatriculasController) CreateMatricula(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		IDEstudiante string `json:"id_estudiantes" validar:"requerido,id"`
+		IDAsignacion string `json:"id_profesores_ciclos_asignaturas" validar:"requerido,id"`
 	}
 
-	idRegistroNotas, err := config.GenerateID()
-	if err != nil {
-		log.Printf("Error al generar ID de registro de notas: %v", err)
-		http.Error(w, "Error al crear registro de notas", http.StatusInternalServerError)
+	if !leerCuerpo(w, r, &input) {
 		return
 	}
 
-	_, err = c.DB.Exec(
-		"INSERT INTO registro_notas (id_, id_registro_notas, id_matriculas, nota1, nota2, sup, version) VALUES (?, ?, ?, ?, ?, ?, ?)",
-		idRegistro, idRegistroNotas, idMatricula, 0, 0, 0, 1,
-	)
-	if err != nil {
-		log.Printf("Error al insertar registro de notas: %v", err)
-		// Eliminar la matrícula creada para mantener consistencia
-		c.DB.Exec("DELETE FROM matriculas WHERE id_matriculas = ?", idMatricula)
-		http.Error(w, "Error al crear registro de notas", http.StatusInternalServerError)
+	// Un estudiante solo puede matricularse a sí mismo
+	if esEstudianteAjeno(r, input.IDEstudiante) {
+		responderError(w, r, http.StatusForbidden, models.CodSinPermiso, msgSinPermiso)
 		return
 	}
 
 	nuevaMatricula := models.Matricula{
-		ID:          id,
-		IDMatricula: idMatricula,
 		IDEstudiante: input.IDEstudiante,
 		IDAsignacion: input.IDAsignacion,
-		Version:     1,
 	}
-
-	// Notificar al middleware
-	if err := middleware.SendToMiddleware("CREATE", "matriculas", nuevaMatricula); err != nil {
-		log.Printf("Error al notificar al middleware: %v", err)
+	nuevoRegistro, err := c.Repo.Create(r.Context(), &nuevaMatricula)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderNoEncontrado(w, r, err)
+		return
+	} else if errors.Is(err, repository.ErrDuplicate) {
+		responderError(w, r, http.StatusBadRequest, models.CodYaMatriculado, "El estudiante ya está matriculado en esta asignatura")
+		return
+	} else if responderReglaMatricula(w, r, err) {
+		return
+	} else if err != nil {
+		log.Printf("Error al crear matrícula: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear matrícula")
+		return
 	}
 
+	nuevoRegistro.IDEstudiante, nuevoRegistro.IDAsignacion = nuevaMatricula.IDEstudiante, nuevaMatricula.IDAsignacion
+	c.publicar(models.OperacionCrear, repository.TablaMatriculas, nuevaMatricula, nuevaMatricula)
+	c.publicar(models.OperacionCrear, repository.TablaNotas, nuevoRegistro, nuevaMatricula)
+
+	setETag(w, nuevaMatricula.Version)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(nuevaMatricula)
@@ -256,152 +189,108 @@ func (c *MatriculasController) UpdateMatricula(w http.ResponseWriter, r *http.Re
 	id := vars["id"]
 
 	var input struct {
-		IDEstudiante string `json:"id_estudiantes"`
-		IDAsignacion string `json:"id_profesores_ciclos_asignaturas"`
-	}
-
-	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		http.Error(w, "Datos inválidos", http.StatusBadRequest)
-		return
+		IDEstudiante string `json:"id_estudiantes" validar:"requerido,id"`
+		IDAsignacion string `json:"id_profesores_ciclos_asignaturas" validar:"requerido,id"`
 	}
 
-	if input.IDEstudiante == "" || input.IDAsignacion == "" {
-		http.Error(w, "Todos los campos son requeridos", http.StatusBadRequest)
+	if !leerCuerpo(w, r, &input) {
 		return
 	}
 
 	// Verificar si existe la matrícula
-	var matricula models.Matricula
-	err := c.DB.QueryRow("SELECT id_, id_matriculas, id_estudiantes, id_profesores_ciclos_asignaturas, version FROM matriculas WHERE id_matriculas = ?", id).
-		Scan(&matricula.ID, &matricula.IDMatricula, &matricula.IDEstudiante, &matricula.IDAsignacion, &matricula.Version)
-	if err == sql.ErrNoRows {
-		http.Error(w, "Matrícula no encontrada", http.StatusNotFound)
+	matricula, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodMatriculaNoEncontrada, "Matrícula no encontrada")
 		return
 	} else if err != nil {
 		log.Printf("Error al verificar matrícula: %v", err)
-		http.Error(w, "Error al actualizar matrícula", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar matrícula")
 		return
 	}
 
-	// Verificar si existe el estudiante
-	var estudiante models.Estudiante
-	err = c.DB.QueryRow("SELECT id_, id_estudiantes, nombre, version FROM estudiantes WHERE id_estudiantes = ?", input.IDEstudiante).
-		Scan(&estudiante.ID, &estudiante.IDEstudiante, &estudiante.Nombre, &estudiante.Version)
-	if err == sql.ErrNoRows {
-		http.Error(w, "Estudiante no encontrado", http.StatusNotFound)
-		return
-	} else if err != nil {
-		log.Printf("Error al verificar estudiante: %v", err)
-		http.Error(w, "Error al actualizar matrícula", http.StatusInternalServerError)
+	if !verificarIfMatch(w, r, matricula.Version) {
 		return
 	}
 
-	// Verificar si existe la asignación
-	var asignacion models.Asignacion
-	err = c.DB.QueryRow("SELECT id_, id_profesores_ciclos_asignaturas, id_profesores, id_asignaturas, id_ciclos, version FROM profesores_ciclos_asignaturas WHERE id_profesores_ciclos_asignaturas = ?", input.IDAsignacion).
-		Scan(&asignacion.ID, &asignacion.IDAsignacion, &asignacion.IDProfesor, &asignacion.IDAsignatura, &asignacion.IDCiclo, &asignacion.Version)
-	if err == sql.ErrNoRows {
-		http.Error(w, "Asignación no encontrada", http.StatusNotFound)
+	// Actualizar matrícula solo si la versión no cambió
+	matriculaActualizada := models.Matricula{
+		ID:           matricula.ID,
+		IDMatricula:  matricula.IDMatricula,
+		IDEstudiante: input.IDEstudiante,
+		IDAsignacion: input.IDAsignacion,
+		Version:      matricula.Version,
+	}
+	err = c.Repo.Update(r.Context(), &matriculaActualizada)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderNoEncontrado(w, r, err)
 		return
-	} else if err != nil {
-		log.Printf("Error al verificar asignación: %v", err)
-		http.Error(w, "Error al actualizar matrícula", http.StatusInternalServerError)
+	} else if errors.Is(err, repository.ErrDuplicate) {
+		responderError(w, r, http.StatusBadRequest, models.CodYaMatriculado, "El estudiante ya está matriculado en esta asignatura")
 		return
-	}
-
-	// Verificar si ya existe otra matrícula con los mismos datos
-	var count int
-	err = c.DB.QueryRow("SELECT COUNT(*) FROM matriculas WHERE id_estudiantes = ? AND id_profesores_ciclos_asignaturas = ? AND id_matriculas != ?", input.IDEstudiante, input.IDAsignacion, id).Scan(&count)
-	if err != nil {
-		log.Printf("Error al verificar matrícula existente: %v", err)
-		http.Error(w, "Error al actualizar matrícula", http.StatusInternalServerError)
+	} else if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
 		return
-	}
-
-	if count > 0 {
-		http.Error(w, "El estudiante ya está matriculado en esta asignatura", http.StatusBadRequest)
+	} else if responderReglaMatricula(w, r, err) {
 		return
-	}
-
-	// Actualizar matrícula
-	newVersion := matricula.Version + 1
-	_, err = c.DB.Exec(
-		"UPDATE matriculas SET id_estudiantes = ?, id_profesores_ciclos_asignaturas = ?, version = ? WHERE id_matriculas = ?",
-		input.IDEstudiante, input.IDAsignacion, newVersion, id,
-	)
-	if err != nil {
+	} else if err != nil {
 		log.Printf("Error al actualizar matrícula: %v", err)
-		http.Error(w, "Error al actualizar matrícula", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar matrícula")
 		return
 	}
 
-	matriculaActualizada := models.Matricula{
-		ID:          matricula.ID,
-		IDMatricula: matricula.IDMatricula,
-		IDEstudiante: input.IDEstudiante,
-		IDAsignacion: input.IDAsignacion,
-		Version:     newVersion,
-	}
-
-	// Notificar al middleware
-	if err := middleware.SendToMiddleware("UPDATE", "matriculas", matriculaActualizada); err != nil {
-		log.Printf("Error al notificar al middleware: %v", err)
-	}
+	// Avisar también al estudiante y la asignación anteriores si cambiaron
+	c.publicar(models.OperacionActualizar, repository.TablaMatriculas, matriculaActualizada, matriculaActualizada,
+		TemaDeEstudiante(matricula.IDEstudiante), TemaDeAsignacion(matricula.IDAsignacion))
 
+	setETag(w, matriculaActualizada.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(matriculaActualizada)
 }
 
-// DeleteMatricula elimina una matrícula
+// DeleteMatricula elimina una matrícula y su registro de notas en una sola transacción
 func (c *MatriculasController) DeleteMatricula(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
 	// Verificar si existe la matrícula
-	var matricula models.Matricula
-	err := c.DB.QueryRow("SELECT id_, id_matriculas, id_estudiantes, id_profesores_ciclos_asignaturas, version FROM matriculas WHERE id_matriculas = ?", id).
-		Scan(&matricula.ID, &matricula.IDMatricula, &matricula.IDEstudiante, &matricula.IDAsignacion, &matricula.Version)
-	if err == sql.ErrNoRows {
-		http.Error(w, "Matrícula no encontrada", http.StatusNotFound)
+	matricula, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodMatriculaNoEncontrada, "Matrícula no encontrada")
 		return
 	} else if err != nil {
 		log.Printf("Error al verificar matrícula: %v", err)
-		http.Error(w, "Error al eliminar matrícula", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar matrícula")
 		return
 	}
 
-	// Obtener el registro de notas asociado
-	var registro models.Nota
-	err = c.DB.QueryRow("SELECT id_, id_registro_notas, id_matriculas, nota1, nota2, sup, version FROM registro_notas WHERE id_matriculas = ?", id).
-		Scan(&registro.ID, &registro.IDNota, &registro.IDMatricula, &registro.Nota1, &registro.Nota2, &registro.Sup, &registro.Version)
-	
-	// Eliminar primero el registro de notas (por la restricción de clave foránea)
-	if err == nil {
-		_, err = c.DB.Exec("DELETE FROM registro_notas WHERE id_matriculas = ?", id)
-		if err != nil {
-			log.Printf("Error al eliminar registro de notas: %v", err)
-			http.Error(w, "Error al eliminar registro de notas", http.StatusInternalServerError)
-			return
-		}
-
-		// Notificar al middleware sobre la eliminación del registro de notas
-		if err := middleware.SendToMiddleware("DELETE", "registro_notas", registro); err != nil {
-			log.Printf("Error al notificar al middleware: %v", err)
-		}
+	// Un estudiante solo puede eliminar sus propias matrículas
+	if esEstudianteAjeno(r, matricula.IDEstudiante) {
+		responderError(w, r, http.StatusForbidden, models.CodSinPermiso, msgSinPermiso)
+		return
 	}
 
-	// Eliminar la matrícula
-	_, err = c.DB.Exec("DELETE FROM matriculas WHERE id_matriculas = ?", id)
-	if err != nil {
+	if !verificarIfMatch(w, r, matricula.Version) {
+		return
+	}
+
+	registro, err := c.Repo.Delete(r.Context(), matricula)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodMatriculaNoEncontrada, "Matrícula no encontrada")
+		return
+	} else if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
 		log.Printf("Error al eliminar matrícula: %v", err)
-		http.Error(w, "Error al eliminar matrícula", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar matrícula")
 		return
 	}
 
-	// Notificar al middleware
-	if err := middleware.SendToMiddleware("DELETE", "matriculas", matricula); err != nil {
-		log.Printf("Error al notificar al middleware: %v", err)
+	if registro != nil {
+		registro.IDEstudiante, registro.IDAsignacion = matricula.IDEstudiante, matricula.IDAsignacion
+		c.publicar(models.OperacionEliminar, repository.TablaNotas, *registro, matricula)
 	}
+	c.publicar(models.OperacionEliminar, repository.TablaMatriculas, matricula, matricula)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"message": "Matrícula eliminada correctamente"})
@@ -1,80 +1,67 @@
 package controllers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"server_estudiantes/config"
 	"server_estudiantes/models"
+	"server_estudiantes/repository"
+	"server_estudiantes/validacion"
 
 	"github.com/gorilla/mux"
 )
 
 // NotasController maneja las solicitudes relacionadas con notas
 type NotasController struct {
-	DB *sql.DB
+	Repo         repository.NotaRepo
+	Asignaciones repository.AsignacionRepo
+	Politica     config.PoliticaCalificacion
+	Hub          *Hub
 }
 
 // NewNotasController crea una nueva instancia del controlador de notas
-func NewNotasController(db *sql.DB) *NotasController {
-	return &NotasController{DB: db}
+func NewNotasController(repo repository.NotaRepo, asignaciones repository.AsignacionRepo, politica config.PoliticaCalificacion, hub *Hub) *NotasController {
+	return &NotasController{Repo: repo, Asignaciones: asignaciones, Politica: politica, Hub: hub}
 }
 
-// GetAllNotas obtiene todos los registros de notas
+// publicar notifica la actualización de un registro de notas a los suscriptores
+// del estudiante, de la asignación y de la tabla
+func (c *NotasController) publicar(n models.Nota) {
+	c.Hub.Publicar(models.OperacionActualizar, repository.TablaNotas, n,
+		TemaDeEstudiante(n.IDEstudiante), TemaDeAsignacion(n.IDAsignacion), TemaDeTabla(repository.TablaNotas))
+}
+
+// calcular completa los campos calculados de un registro de notas
+func (c *NotasController) calcular(n *models.Nota) {
+	n.Promedio, n.NotaFinal, n.Estado = c.Politica.Evaluar(n.Calificado, n.Nota1, n.Nota2, n.Sup)
+}
+
+// GetAllNotas obtiene una página de registros de notas filtrada y ordenada
 func (c *NotasController) GetAllNotas(w http.ResponseWriter, r *http.Request) {
-	rows, err := c.DB.Query(`
-		SELECT 
-			rn.id_, 
-			rn.id_registro_notas, 
-			rn.id_matriculas, 
-			rn.nota1, 
-			rn.nota2, 
-			rn.sup, 
-			rn.version,
-			e.nombre AS nombre_estudiante,
-			p.nombre AS nombre_profesor,
-			a.nombre_asignatura,
-			c.ciclo
-		FROM registro_notas rn
-		JOIN matriculas m ON rn.id_matriculas = m.id_matriculas
-		JOIN estudiantes e ON m.id_estudiantes = e.id_estudiantes
-		JOIN profesores_ciclos_asignaturas pca ON m.id_profesores_ciclos_asignaturas = pca.id_profesores_ciclos_asignaturas
-		JOIN profesores p ON pca.id_profesores = p.id_profesores
-		JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
-		JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
-	`)
+	p, err := leerListParams(r)
 	if err != nil {
+		responderError(w, r, http.StatusBadRequest, models.CodParametroInvalido, err.Error())
+		return
+	}
+
+	notas, total, siguiente, err := c.Repo.List(r.Context(), p)
+	if errors.Is(err, repository.ErrInvalidParam) {
+		responderError(w, r, http.StatusBadRequest, models.CodParametroInvalido, err.Error())
+		return
+	} else if err != nil {
 		log.Printf("Error al consultar notas: %v", err)
-		http.Error(w, "Error al obtener notas", http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	notas := []models.Nota{}
-	for rows.Next() {
-		var n models.Nota
-		if err := rows.Scan(
-			&n.ID, 
-			&n.IDNota, 
-			&n.IDMatricula, 
-			&n.Nota1, 
-			&n.Nota2, 
-			&n.Sup, 
-			&n.Version,
-			&n.NombreEstudiante,
-			&n.NombreProfesor,
-			&n.NombreAsignatura,
-			&n.Ciclo,
-		); err != nil {
-			log.Printf("Error al escanear nota: %v", err)
-			http.Error(w, "Error al procesar datos de notas", http.StatusInternalServerError)
-			return
-		}
-		notas = append(notas, n)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener notas")
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(notas)
+	for i := range notas {
+		c.calcular(&notas[i])
+	}
+
+	responderPagina(w, notas, total, siguiente, p)
 }
 
 // GetNota obtiene un registro de notas por su ID
@@ -82,51 +69,24 @@ func (c *NotasController) GetNota(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var n models.Nota
-	err := c.DB.QueryRow(`
-		SELECT 
-			rn.id_, 
-			rn.id_registro_notas, 
-			rn.id_matriculas, 
-			rn.nota1, 
-			rn.nota2, 
-			rn.sup, 
-			rn.version,
-			e.nombre AS nombre_estudiante,
-			p.nombre AS nombre_profesor,
-			a.nombre_asignatura,
-			c.ciclo
-		FROM registro_notas rn
-		JOIN matriculas m ON rn.id_matriculas = m.id_matriculas
-		JOIN estudiantes e ON m.id_estudiantes = e.id_estudiantes
-		JOIN profesores_ciclos_asignaturas pca ON m.id_profesores_ciclos_asignaturas = pca.id_profesores_ciclos_asignaturas
-		JOIN profesores p ON pca.id_profesores = p.id_profesores
-		JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
-		JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
-		WHERE rn.id_registro_notas = ?
-	`, id).Scan(
-		&n.ID, 
-		&n.IDNota, 
-		&n.IDMatricula, 
-		&n.Nota1, 
-		&n.Nota2, 
-		&n.Sup, 
-		&n.Version,
-		&n.NombreEstudiante,
-		&n.NombreProfesor,
-		&n.NombreAsignatura,
-		&n.Ciclo,
-	)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Registro de notas no encontrado", http.StatusNotFound)
+	n, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodNotaNoEncontrada, "Registro de notas no encontrado")
 		return
 	} else if err != nil {
 		log.Printf("Error al consultar registro de notas: %v", err)
-		http.Error(w, "Error al obtener registro de notas", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener registro de notas")
+		return
+	}
+
+	if esEstudianteAjeno(r, n.IDEstudiante) {
+		responderError(w, r, http.StatusForbidden, models.CodSinPermiso, msgSinPermiso)
 		return
 	}
 
+	c.calcular(&n)
+
+	setETag(w, n.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(n)
 }
@@ -136,71 +96,192 @@ func (c *NotasController) GetNotasByEstudiante(w http.ResponseWriter, r *http.Re
 	vars := mux.Vars(r)
 	idEstudiante := vars["id"]
 
-	// Verificar si existe el estudiante
-	var estudiante models.Estudiante
-	err := c.DB.QueryRow("SELECT id_, id_estudiantes, nombre, version FROM estudiantes WHERE id_estudiantes = ?", idEstudiante).
-		Scan(&estudiante.ID, &estudiante.IDEstudiante, &estudiante.Nombre, &estudiante.Version)
-	if err == sql.ErrNoRows {
-		http.Error(w, "Estudiante no encontrado", http.StatusNotFound)
+	notas, err := c.Repo.ListByEstudiante(r.Context(), idEstudiante)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodEstudianteNoEncontrado, "Estudiante no encontrado")
 		return
 	} else if err != nil {
-		log.Printf("Error al verificar estudiante: %v", err)
-		http.Error(w, "Error al obtener notas del estudiante", http.StatusInternalServerError)
-		return
-	}
-
-	rows, err := c.DB.Query(`
-		SELECT 
-			rn.id_, 
-			rn.id_registro_notas, 
-			rn.id_matriculas, 
-			rn.nota1, 
-			rn.nota2, 
-			rn.sup, 
-			rn.version,
-			e.nombre AS nombre_estudiante,
-			p.nombre AS nombre_profesor,
-			a.nombre_asignatura,
-			c.ciclo
-		FROM registro_notas rn
-		JOIN matriculas m ON rn.id_matriculas = m.id_matriculas
-		JOIN estudiantes e ON m.id_estudiantes = e.id_estudiantes
-		JOIN profesores_ciclos_asignaturas pca ON m.id_profesores_ciclos_asignaturas = pca.id_profesores_ciclos_asignaturas
-		JOIN profesores p ON pca.id_profesores = p.id_profesores
-		JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
-		JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
-		WHERE e.id_estudiantes = ?
-	`, idEstudiante)
-	if err != nil {
 		log.Printf("Error al consultar notas del estudiante: %v", err)
-		http.Error(w, "Error al obtener notas del estudiante", http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	notas := []models.Nota{}
-	for rows.Next() {
-		var n models.Nota
-		if err := rows.Scan(
-			&n.ID, 
-			&n.IDNota, 
-			&n.IDMatricula, 
-			&n.Nota1, 
-			&n.Nota2, 
-			&n.Sup, 
-			&n.Version,
-			&n.NombreEstudiante,
-			&n.NombreProfesor,
-			&n.NombreAsignatura,
-			&n.Ciclo,
-		); err != nil {
-			log.Printf("Error al escanear nota: %v", err)
-			http.Error(w, "Error al procesar datos de notas", http.StatusInternalServerError)
-			return
-		}
-		notas = append(notas, n)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener notas del estudiante")
+		return
+	}
+
+	for i := range notas {
+		c.calcular(&notas[i])
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(notas)
 }
+
+// notaInput representa los datos enviados para actualizar un registro de notas
+type notaInput struct {
+	IDNota  string  `json:"id_registro_notas" validar:"id"`
+	Nota1   float64 `json:"nota1" validar:"min=0"`
+	Nota2   float64 `json:"nota2" validar:"min=0"`
+	Sup     int     `json:"sup" validar:"min=0"`
+	Version int     `json:"version" validar:"requerido,min=1"`
+}
+
+// validarMaximo verifica que las notas no superen la nota máxima de la política de
+// calificación, que no puede declararse en la etiqueta porque depende de la configuración
+func (n notaInput) validarMaximo(prefijo string, notaMaxima float64) []models.ErrorCampo {
+	var errores []models.ErrorCampo
+	msg := fmt.Sprintf("Debe ser menor o igual a %g", notaMaxima)
+	for _, campo := range []struct {
+		nombre string
+		valor  float64
+	}{
+		{"nota1", n.Nota1},
+		{"nota2", n.Nota2},
+		{"sup", float64(n.Sup)},
+	} {
+		if campo.valor > notaMaxima {
+			errores = append(errores, validacion.Campo(prefijo+campo.nombre, validacion.ReglaMax, msg))
+		}
+	}
+	return errores
+}
+
+// UpdateNota registra las calificaciones de un registro de notas
+func (c *NotasController) UpdateNota(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var input notaInput
+	if !leerCuerpo(w, r, &input) {
+		return
+	}
+
+	if !responderValidacion(w, r, input.validarMaximo("", c.Politica.NotaMaxima)) {
+		return
+	}
+
+	// Verificar si existe el registro de notas
+	registro, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodNotaNoEncontrada, "Registro de notas no encontrado")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar registro de notas: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar registro de notas")
+		return
+	}
+
+	// Un profesor solo califica las asignaciones que dicta
+	if esProfesorAjeno(r, registro.IDProfesor) {
+		responderError(w, r, http.StatusForbidden, models.CodSinPermiso, msgSinPermiso)
+		return
+	}
+
+	if !verificarIfMatch(w, r, registro.Version) {
+		return
+	}
+
+	// Actualizar solo si la versión no cambió desde que el cliente la leyó
+	registroActualizado := registro
+	registroActualizado.Nota1 = input.Nota1
+	registroActualizado.Nota2 = input.Nota2
+	registroActualizado.Sup = input.Sup
+	registroActualizado.Calificado = true
+	registroActualizado.Version = input.Version
+	err = c.Repo.Update(r.Context(), &registroActualizado)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
+		log.Printf("Error al actualizar registro de notas: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar registro de notas")
+		return
+	}
+	c.calcular(&registroActualizado)
+
+	c.publicar(registroActualizado)
+
+	setETag(w, registroActualizado.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(registroActualizado)
+}
+
+// UpdateNotasByAsignacion registra en bloque las calificaciones de una asignación
+func (c *NotasController) UpdateNotasByAsignacion(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idAsignacion := vars["id"]
+
+	var input []notaInput
+	if !leerCuerpo(w, r, &input) {
+		return
+	}
+
+	if len(input) == 0 {
+		responderError(w, r, http.StatusBadRequest, models.CodValidacion, "Debe enviar al menos un registro de notas")
+		return
+	}
+
+	// En el registro en bloque cada elemento debe identificar su registro de notas
+	var errores []models.ErrorCampo
+	for i, n := range input {
+		prefijo := fmt.Sprintf("[%d].", i)
+		if n.IDNota == "" {
+			errores = append(errores, validacion.Campo(prefijo+"id_registro_notas", validacion.ReglaRequerido, "El campo es requerido"))
+		}
+		errores = append(errores, n.validarMaximo(prefijo, c.Politica.NotaMaxima)...)
+	}
+	if !responderValidacion(w, r, errores) {
+		return
+	}
+
+	// Un profesor solo califica las asignaciones que dicta
+	asignacion, err := c.Asignaciones.Get(r.Context(), idAsignacion)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodAsignacionNoEncontrada, "Asignación no encontrada")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar asignación: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar notas")
+		return
+	}
+	if esProfesorAjeno(r, asignacion.IDProfesor) {
+		responderError(w, r, http.StatusForbidden, models.CodSinPermiso, msgSinPermiso)
+		return
+	}
+
+	actualizadas := make([]models.Nota, 0, len(input))
+	for _, n := range input {
+		actualizadas = append(actualizadas, models.Nota{
+			IDNota:     n.IDNota,
+			Nota1:      n.Nota1,
+			Nota2:      n.Nota2,
+			Sup:        n.Sup,
+			Calificado: true,
+			Version:    n.Version,
+		})
+	}
+
+	// Todas las notas se actualizan en una sola transacción
+	err = c.Repo.UpdateByAsignacion(r.Context(), idAsignacion, actualizadas)
+	var nf *repository.NotFoundError
+	var ve *repository.VersionError
+	if errors.As(err, &nf) && nf.Entidad == repository.EntidadNota {
+		responderError(w, r, http.StatusNotFound, models.CodNotaNoEncontrada, fmt.Sprintf("Registro de notas %s no encontrado en la asignación", nf.ID))
+		return
+	} else if errors.Is(err, repository.ErrNotFound) {
+		responderNoEncontrado(w, r, err)
+		return
+	} else if errors.As(err, &ve) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, fmt.Sprintf("El registro de notas %s fue modificado por otro usuario", ve.ID))
+		return
+	} else if err != nil {
+		log.Printf("Error al actualizar notas: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar notas")
+		return
+	}
+
+	for i := range actualizadas {
+		c.calcular(&actualizadas[i])
+		c.publicar(actualizadas[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(actualizadas)
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// OutboxController expone los eventos de sincronización que agotaron sus reintentos
+type OutboxController struct {
+	Repo repository.OutboxRepo
+}
+
+// NewOutboxController crea una nueva instancia del controlador del outbox
+func NewOutboxController(repo repository.OutboxRepo) *OutboxController {
+	return &OutboxController{Repo: repo}
+}
+
+// GetEventosFallidos lista los eventos que no se pudieron enviar al middleware
+func (c *OutboxController) GetEventosFallidos(w http.ResponseWriter, r *http.Request) {
+	eventos, err := c.Repo.ListFallidos(r.Context())
+	if err != nil {
+		log.Printf("Error al consultar eventos fallidos: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener eventos fallidos")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(eventos)
+}
+
+// ReintentarEvento devuelve un evento fallido a la cola de envío
+func (c *OutboxController) ReintentarEvento(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	err := c.Repo.Reintentar(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodEventoNoEncontrado, "Evento fallido no encontrado")
+		return
+	} else if err != nil {
+		log.Printf("Error al reintentar evento: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al reintentar evento")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Evento devuelto a la cola de envío"})
+}
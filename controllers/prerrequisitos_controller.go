@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
+
+	"github.com/gorilla/mux"
+)
+
+// PrerrequisitosController maneja el plan de estudios: los prerrequisitos entre
+// asignaturas y las asignaturas a las que puede optar cada estudiante
+type PrerrequisitosController struct {
+	Repo        repository.PrerrequisitoRepo
+	Asignaturas repository.AsignaturaRepo
+}
+
+// NewPrerrequisitosController crea una nueva instancia del controlador de prerrequisitos
+func NewPrerrequisitosController(repo repository.PrerrequisitoRepo, asignaturas repository.AsignaturaRepo) *PrerrequisitosController {
+	return &PrerrequisitosController{Repo: repo, Asignaturas: asignaturas}
+}
+
+// GetPlanEstudios obtiene el grafo completo de asignaturas y prerrequisitos
+func (c *PrerrequisitosController) GetPlanEstudios(w http.ResponseWriter, r *http.Request) {
+	asignaturas, err := c.Asignaturas.List(r.Context())
+	if err != nil {
+		log.Printf("Error al consultar asignaturas del plan de estudios: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener el plan de estudios")
+		return
+	}
+	prerrequisitos, err := c.Repo.List(r.Context())
+	if err != nil {
+		log.Printf("Error al consultar prerrequisitos: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener el plan de estudios")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.PlanEstudios{Asignaturas: asignaturas, Prerrequisitos: prerrequisitos})
+}
+
+// GetPrerrequisitosByAsignatura obtiene las asignaturas que exige una asignatura
+func (c *PrerrequisitosController) GetPrerrequisitosByAsignatura(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idAsignatura := vars["id"]
+
+	prerrequisitos, err := c.Repo.ListByAsignatura(r.Context(), idAsignatura)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodAsignaturaNoEncontrada, "Asignatura no encontrada")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar prerrequisitos de la asignatura: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener prerrequisitos")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prerrequisitos)
+}
+
+// CreatePrerrequisito agrega un prerrequisito a una asignatura, rechazando los que
+// formarían un ciclo en el plan de estudios
+func (c *PrerrequisitosController) CreatePrerrequisito(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idAsignatura := vars["id"]
+
+	var input struct {
+		IDAsignaturaRequerida string `json:"id_asignatura_requerida" validar:"requerido,id"`
+	}
+
+	if !leerCuerpo(w, r, &input) {
+		return
+	}
+
+	nuevoPrerrequisito := models.Prerrequisito{
+		IDAsignatura:          idAsignatura,
+		IDAsignaturaRequerida: input.IDAsignaturaRequerida,
+	}
+	err := c.Repo.Create(r.Context(), &nuevoPrerrequisito)
+	var ciclo *repository.CicloError
+	if errors.Is(err, repository.ErrNotFound) {
+		responderNoEncontrado(w, r, err)
+		return
+	} else if errors.Is(err, repository.ErrDuplicate) {
+		responderError(w, r, http.StatusBadRequest, models.CodPrerrequisitoDuplicado, "La asignatura ya tiene este prerrequisito")
+		return
+	} else if errors.As(err, &ciclo) {
+		responderErrorDetalles(w, r, http.StatusConflict, models.CodCicloPrerrequisitos,
+			"El prerrequisito formaría un ciclo en el plan de estudios", map[string][]string{"ciclo": ciclo.Ruta})
+		return
+	} else if err != nil {
+		log.Printf("Error al insertar prerrequisito: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear prerrequisito")
+		return
+	}
+
+	setETag(w, nuevoPrerrequisito.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(nuevoPrerrequisito)
+}
+
+// DeletePrerrequisito elimina un prerrequisito
+func (c *PrerrequisitosController) DeletePrerrequisito(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Verificar si el prerrequisito existe
+	prerrequisito, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodPrerrequisitoNoEncontrado, "Prerrequisito no encontrado")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar prerrequisito: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar prerrequisito")
+		return
+	}
+
+	if !verificarIfMatch(w, r, prerrequisito.Version) {
+		return
+	}
+
+	err = c.Repo.Delete(r.Context(), prerrequisito)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
+		log.Printf("Error al eliminar prerrequisito: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar prerrequisito")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Prerrequisito eliminado correctamente"})
+}
+
+// GetAsignaturasElegibles obtiene las asignaturas que el estudiante aún no aprobó y
+// cuyos prerrequisitos ya aprobó
+func (c *PrerrequisitosController) GetAsignaturasElegibles(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	idEstudiante := vars["id"]
+
+	asignaturas, err := c.Repo.Elegibles(r.Context(), idEstudiante)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodEstudianteNoEncontrado, "Estudiante no encontrado")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar asignaturas elegibles: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener asignaturas elegibles")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(asignaturas)
+}
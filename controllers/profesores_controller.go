@@ -1,45 +1,34 @@
 package controllers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 	"server_estudiantes/models"
+	"server_estudiantes/repository"
 
 	"github.com/gorilla/mux"
 )
 
 // ProfesoresController maneja las solicitudes relacionadas con profesores
 type ProfesoresController struct {
-	DB *sql.DB
+	Repo repository.ProfesorRepo
 }
 
 // NewProfesoresController crea una nueva instancia del controlador de profesores
-func NewProfesoresController(db *sql.DB) *ProfesoresController {
-	return &ProfesoresController{DB: db}
+func NewProfesoresController(repo repository.ProfesorRepo) *ProfesoresController {
+	return &ProfesoresController{Repo: repo}
 }
 
 // GetAllProfesores obtiene todos los profesores
 func (c *ProfesoresController) GetAllProfesores(w http.ResponseWriter, r *http.Request) {
-	rows, err := c.DB.Query("SELECT id_, id_profesores, nombre, version FROM profesores")
+	profesores, err := c.Repo.List(r.Context())
 	if err != nil {
 		log.Printf("Error al consultar profesores: %v", err)
-		http.Error(w, "Error al obtener profesores", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener profesores")
 		return
 	}
-	defer rows.Close()
-
-	profesores := []models.Profesor{}
-	for rows.Next() {
-		var p models.Profesor
-		if err := rows.Scan(&p.ID, &p.IDProfesor, &p.Nombre, &p.Version); err != nil {
-			log.Printf("Error al escanear profesor: %v", err)
-			http.Error(w, "Error al procesar datos de profesores", http.StatusInternalServerError)
-			return
-		}
-		profesores = append(profesores, p)
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(profesores)
@@ -50,19 +39,124 @@ func (c *ProfesoresController) GetProfesor(w http.ResponseWriter, r *http.Reques
 	vars := mux.Vars(r)
 	id := vars["id"]
 
-	var p models.Profesor
-	err := c.DB.QueryRow("SELECT id_, id_profesores, nombre, version FROM profesores WHERE id_profesores = ?", id).
-		Scan(&p.ID, &p.IDProfesor, &p.Nombre, &p.Version)
-
-	if err == sql.ErrNoRows {
-		http.Error(w, "Profesor no encontrado", http.StatusNotFound)
+	p, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodProfesorNoEncontrado, "Profesor no encontrado")
 		return
 	} else if err != nil {
 		log.Printf("Error al consultar profesor: %v", err)
-		http.Error(w, "Error al obtener profesor", http.StatusInternalServerError)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al obtener profesor")
 		return
 	}
 
+	setETag(w, p.Version)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(p)
 }
+
+// CreateProfesor crea un nuevo profesor
+func (c *ProfesoresController) CreateProfesor(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Nombre string `json:"nombre" validar:"requerido,max=100"`
+	}
+
+	if !leerCuerpo(w, r, &input) {
+		return
+	}
+
+	nuevoProfesor := models.Profesor{Nombre: input.Nombre}
+	if err := c.Repo.Create(r.Context(), &nuevoProfesor); err != nil {
+		log.Printf("Error al insertar profesor: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear profesor")
+		return
+	}
+
+	setETag(w, nuevoProfesor.Version)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(nuevoProfesor)
+}
+
+// UpdateProfesor actualiza un profesor existente
+func (c *ProfesoresController) UpdateProfesor(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	var input struct {
+		Nombre string `json:"nombre" validar:"requerido,max=100"`
+	}
+
+	if !leerCuerpo(w, r, &input) {
+		return
+	}
+
+	// Verificar si el profesor existe
+	profesor, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodProfesorNoEncontrado, "Profesor no encontrado")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar profesor: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar profesor")
+		return
+	}
+
+	if !verificarIfMatch(w, r, profesor.Version) {
+		return
+	}
+
+	// Actualizar profesor solo si la versión no cambió
+	profesorActualizado := profesor
+	profesorActualizado.Nombre = input.Nombre
+	err = c.Repo.Update(r.Context(), &profesorActualizado)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
+		log.Printf("Error al actualizar profesor: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al actualizar profesor")
+		return
+	}
+
+	setETag(w, profesorActualizado.Version)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(profesorActualizado)
+}
+
+// DeleteProfesor elimina un profesor
+func (c *ProfesoresController) DeleteProfesor(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id := vars["id"]
+
+	// Verificar si el profesor existe
+	profesor, err := c.Repo.Get(r.Context(), id)
+	if errors.Is(err, repository.ErrNotFound) {
+		responderError(w, r, http.StatusNotFound, models.CodProfesorNoEncontrado, "Profesor no encontrado")
+		return
+	} else if err != nil {
+		log.Printf("Error al consultar profesor: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar profesor")
+		return
+	}
+
+	if !verificarIfMatch(w, r, profesor.Version) {
+		return
+	}
+
+	// Eliminar profesor si no tiene asignaciones
+	err = c.Repo.Delete(r.Context(), profesor)
+	if errors.Is(err, repository.ErrInUse) {
+		responderError(w, r, http.StatusBadRequest, models.CodProfesorEnUso, "No se puede eliminar el profesor porque tiene asignaciones")
+		return
+	} else if errors.Is(err, repository.ErrVersionConflict) {
+		responderError(w, r, http.StatusPreconditionFailed, models.CodVersionNoCoincide, msgVersionNoCoincide)
+		return
+	} else if err != nil {
+		log.Printf("Error al eliminar profesor: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al eliminar profesor")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Profesor eliminado correctamente"})
+}
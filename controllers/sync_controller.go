@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"server_estudiantes/middleware"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
+)
+
+// SyncController recibe los cambios hechos en otras réplicas
+type SyncController struct {
+	Repo repository.SyncRepo
+}
+
+// NewSyncController crea una nueva instancia del controlador de sincronización
+func NewSyncController(repo repository.SyncRepo) *SyncController {
+	return &SyncController{Repo: repo}
+}
+
+// Sync aplica un evento recibido desde el middleware
+func (c *SyncController) Sync(w http.ResponseWriter, r *http.Request) {
+	var evento models.EventoSync
+	if !leerCuerpo(w, r, &evento) {
+		return
+	}
+
+	// Los eventos originados en este servidor ya están aplicados; reenviarlos crearía un ciclo
+	resultado := models.SyncPropio
+	if evento.Source != middleware.Origen {
+		var err error
+		resultado, err = c.Repo.Aplicar(r.Context(), evento)
+		if errors.Is(err, repository.ErrInvalidParam) {
+			responderError(w, r, http.StatusBadRequest, models.CodEventoSyncInvalido, err.Error())
+			return
+		} else if err != nil {
+			log.Printf("Error al aplicar evento de sincronización %s: %v", evento.ID, err)
+			responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al aplicar el evento de sincronización")
+			return
+		}
+	}
+
+	log.Printf("Evento %s (%s %s desde %s): %s", evento.ID, evento.Operacion, evento.Tabla, evento.Source, resultado)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"id": evento.ID, "resultado": resultado})
+}
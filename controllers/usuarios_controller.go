@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"server_estudiantes/auth"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
+)
+
+// UsuariosController maneja la creación de credenciales por parte del administrador
+type UsuariosController struct {
+	Repo        repository.UsuarioRepo
+	Estudiantes repository.EstudianteRepo
+	Profesores  repository.ProfesorRepo
+}
+
+// NewUsuariosController crea una nueva instancia del controlador de usuarios
+func NewUsuariosController(repo repository.UsuarioRepo, estudiantes repository.EstudianteRepo, profesores repository.ProfesorRepo) *UsuariosController {
+	return &UsuariosController{Repo: repo, Estudiantes: estudiantes, Profesores: profesores}
+}
+
+// CreateUsuario crea las credenciales de un usuario con el rol indicado
+func (c *UsuariosController) CreateUsuario(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Usuario      string `json:"usuario" validar:"requerido,max=50"`
+		Password     string `json:"password" validar:"requerido,min=8,max=128"`
+		Rol          string `json:"rol" validar:"requerido,oneof=estudiante|profesor|secretaria|admin"`
+		IDReferencia string `json:"id_referencia" validar:"id"`
+	}
+
+	if !leerCuerpo(w, r, &input) {
+		return
+	}
+
+	// Estudiantes y profesores deben quedar vinculados a su registro
+	var err error
+	switch input.Rol {
+	case auth.RolEstudiante:
+		_, err = c.Estudiantes.Get(r.Context(), input.IDReferencia)
+	case auth.RolProfesor:
+		_, err = c.Profesores.Get(r.Context(), input.IDReferencia)
+	case auth.RolSecretaria, auth.RolAdmin:
+		input.IDReferencia = ""
+	default:
+		responderError(w, r, http.StatusBadRequest, models.CodValidacion, "Rol inválido")
+		return
+	}
+	if errors.Is(err, repository.ErrNotFound) {
+		responderNoEncontrado(w, r, err)
+		return
+	} else if err != nil {
+		log.Printf("Error al verificar referencia del usuario: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear usuario")
+		return
+	}
+
+	hash, err := auth.HashPassword(input.Password)
+	if err != nil {
+		log.Printf("Error al generar hash de contraseña: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear usuario")
+		return
+	}
+
+	nuevoUsuario := models.Usuario{
+		Usuario:      input.Usuario,
+		PasswordHash: hash,
+		Rol:          input.Rol,
+		IDReferencia: input.IDReferencia,
+	}
+	err = c.Repo.Create(r.Context(), &nuevoUsuario)
+	if errors.Is(err, repository.ErrDuplicate) {
+		responderError(w, r, http.StatusBadRequest, models.CodUsuarioDuplicado, "El nombre de usuario ya existe")
+		return
+	} else if err != nil {
+		log.Printf("Error al crear usuario: %v", err)
+		responderError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al crear usuario")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(nuevoUsuario)
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"server_estudiantes/models"
+	"server_estudiantes/validacion"
+	"strings"
+)
+
+// maxCuerpo es el tamaño máximo aceptado para el cuerpo de una solicitud
+const maxCuerpo = 1 << 20
+
+// leerCuerpo decodifica el cuerpo JSON en dst rechazando campos desconocidos y cuerpos
+// demasiado grandes, y aplica las reglas de validación de su tipo. Si algo falla
+// responde al cliente y devuelve false.
+func leerCuerpo(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCuerpo))
+	dec.DisallowUnknownFields()
+
+	err := dec.Decode(dst)
+	if err == nil && dec.Decode(&struct{}{}) != io.EOF {
+		err = errors.New("contenido adicional después del JSON")
+	}
+	if err != nil {
+		responderCuerpoInvalido(w, r, err)
+		return false
+	}
+
+	return responderValidacion(w, r, validacion.Validar(dst))
+}
+
+// responderValidacion responde 400 con todos los errores de campo; devuelve true si no hay errores
+func responderValidacion(w http.ResponseWriter, r *http.Request, errores []models.ErrorCampo) bool {
+	if len(errores) == 0 {
+		return true
+	}
+	responderErrorDetalles(w, r, http.StatusBadRequest, models.CodValidacion, "Los datos enviados no son válidos", errores)
+	return false
+}
+
+// responderCuerpoInvalido traduce un error de decodificación a la respuesta correspondiente
+func responderCuerpoInvalido(w http.ResponseWriter, r *http.Request, err error) {
+	var demasiadoGrande *http.MaxBytesError
+	var tipo *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &demasiadoGrande):
+		responderError(w, r, http.StatusRequestEntityTooLarge, models.CodCuerpoDemasiadoGrande, "El cuerpo de la solicitud es demasiado grande")
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		campo := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		responderErrorDetalles(w, r, http.StatusBadRequest, models.CodCuerpoInvalido, "Datos inválidos",
+			[]models.ErrorCampo{validacion.Campo(campo, "desconocido", "Campo desconocido")})
+	case errors.As(err, &tipo):
+		responderErrorDetalles(w, r, http.StatusBadRequest, models.CodCuerpoInvalido, "Datos inválidos",
+			[]models.ErrorCampo{validacion.Campo(tipo.Field, "tipo", "Tipo de dato inválido")})
+	default:
+		responderError(w, r, http.StatusBadRequest, models.CodCuerpoInvalido, "Datos inválidos")
+	}
+}
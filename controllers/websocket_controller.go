@@ -1,38 +1,274 @@
 package controllers
 
 import (
-    "fmt"
-    "net/http"
-    "github.com/gorilla/websocket"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"server_estudiantes/auth"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Prefijos de los temas a los que se suscriben los clientes WebSocket
+const (
+	TemaEstudiante = "estudiante:"
+	TemaAsignacion = "asignacion:"
+	TemaTabla      = "tabla:"
 )
 
-var upgrader = websocket.Upgrader{
-    CheckOrigin: func(r *http.Request) bool {
-        return true // Permite CORS para desarrollo
-    },
-}
-
-func WebSocketHandler(w http.ResponseWriter, r *http.Request) {
-    conn, err := upgrader.Upgrade(w, r, nil)
-    if err != nil {
-        fmt.Println("Error al establecer WebSocket:", err)
-        return
-    }
-    defer conn.Close()
-
-    for {
-        _, msg, err := conn.ReadMessage()
-        if err != nil {
-            fmt.Println("Error al leer mensaje:", err)
-            break
-        }
-        fmt.Printf("Mensaje recibido: %s\n", msg)
-
-        // Puedes responder al cliente
-        response := fmt.Sprintf("Servidor recibió: %s", msg)
-        if err := conn.WriteMessage(websocket.TextMessage, []byte(response)); err != nil {
-            fmt.Println("Error al enviar mensaje:", err)
-            break
-        }
-    }
+// mensajeCambio es el mensaje enviado a los suscriptores cuando cambia un registro
+type mensajeCambio struct {
+	Tipo      string      `json:"type"`
+	Operacion string      `json:"operation"`
+	Tabla     string      `json:"table"`
+	Data      interface{} `json:"data"`
+}
+
+// solicitudWS es un mensaje enviado por el cliente para gestionar sus suscripciones
+type solicitudWS struct {
+	Accion string `json:"action"`
+	Tema   string `json:"topic"`
+}
+
+// respuestaWS confirma una suscripción o informa un error al cliente
+type respuestaWS struct {
+	Tipo    string `json:"type"`
+	Tema    string `json:"topic,omitempty"`
+	Mensaje string `json:"message,omitempty"`
+}
+
+// clienteWS es una conexión WebSocket con sus temas suscritos
+type clienteWS struct {
+	conn      *websocket.Conn
+	principal auth.Principal
+	envio     chan []byte
+	temas     map[string]bool
+	cierre    sync.Once
+}
+
+// cerrar cierra la conexión una sola vez; la lectura falla y el cliente se da de baja
+func (c *clienteWS) cerrar() {
+	c.cierre.Do(func() { c.conn.Close() })
+}
+
+// Hub mantiene el registro de conexiones WebSocket y reparte los cambios según los temas suscritos
+type Hub struct {
+	Config   config.ConfigWebSocket
+	upgrader websocket.Upgrader
+	mu       sync.RWMutex
+	clientes map[*clienteWS]bool
+}
+
+// NewHub crea un hub sin clientes con los límites indicados
+func NewHub(cfg config.ConfigWebSocket) *Hub {
+	h := &Hub{Config: cfg, clientes: make(map[*clienteWS]bool)}
+	h.upgrader = websocket.Upgrader{CheckOrigin: h.verificarOrigen}
+	return h
+}
+
+// verificarOrigen acepta los orígenes configurados; sin lista, solo el mismo host
+func (h *Hub) verificarOrigen(r *http.Request) bool {
+	origen := r.Header.Get("Origin")
+	if origen == "" {
+		return true
+	}
+	if len(h.Config.Origenes) == 0 {
+		u, err := url.Parse(origen)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+	return h.Config.OrigenPermitido(origen)
+}
+
+// Conectados devuelve la cantidad de clientes WebSocket conectados
+func (h *Hub) Conectados() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clientes)
+}
+
+// Cerrar avisa a todos los clientes que el servidor se detiene y cierra sus conexiones
+func (h *Hub) Cerrar() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	msg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "servidor detenido")
+	for c := range h.clientes {
+		c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(h.Config.EscrituraEspera))
+		c.cerrar()
+	}
+}
+
+// TemaDeEstudiante devuelve el tema de los cambios de un estudiante
+func TemaDeEstudiante(id string) string { return TemaEstudiante + id }
+
+// TemaDeAsignacion devuelve el tema de los cambios de una asignación
+func TemaDeAsignacion(id string) string { return TemaAsignacion + id }
+
+// TemaDeTabla devuelve el tema de los cambios de una tabla
+func TemaDeTabla(tabla string) string { return TemaTabla + tabla }
+
+// Publicar envía un cambio a cada cliente suscrito a alguno de los temas indicados;
+// un cliente suscrito a varios de ellos lo recibe una sola vez
+func (h *Hub) Publicar(operacion, tabla string, data interface{}, temas ...string) {
+	msg, err := json.Marshal(mensajeCambio{Tipo: "change", Operacion: operacion, Tabla: tabla, Data: data})
+	if err != nil {
+		log.Printf("Error al serializar mensaje WebSocket: %v", err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for c := range h.clientes {
+		for _, tema := range temas {
+			if c.temas[tema] {
+				h.encolar(c, msg)
+				break
+			}
+		}
+	}
+}
+
+// encolar agrega un mensaje a la cola del cliente sin bloquear al publicador;
+// un cliente con la cola llena es demasiado lento y se desconecta
+func (h *Hub) encolar(c *clienteWS, msg []byte) {
+	select {
+	case c.envio <- msg:
+	default:
+		log.Printf("Cola de envío llena, se desconecta al cliente WebSocket %s", c.principal.Usuario)
+		c.cerrar()
+	}
+}
+
+// puedeSuscribirse indica si el usuario puede recibir los cambios de un tema:
+// los estudiantes solo los propios, el personal cualquiera
+func puedeSuscribirse(p auth.Principal, tema string) bool {
+	if p.TieneRol(auth.RolProfesor, auth.RolSecretaria, auth.RolAdmin) {
+		return strings.HasPrefix(tema, TemaEstudiante) || strings.HasPrefix(tema, TemaAsignacion) || strings.HasPrefix(tema, TemaTabla)
+	}
+	return p.Rol == auth.RolEstudiante && tema == TemaDeEstudiante(p.IDReferencia)
+}
+
+// HandleWebSocket atiende una conexión WebSocket de un usuario autenticado
+func (h *Hub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
+	p, ok := auth.PrincipalFromContext(r.Context())
+	if !ok {
+		responderError(w, r, http.StatusUnauthorized, models.CodSesionRequerida, "Se requiere iniciar sesión")
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Error al establecer WebSocket: %v", err)
+		return
+	}
+
+	c := &clienteWS{
+		conn:      conn,
+		principal: p,
+		envio:     make(chan []byte, h.Config.ColaEnvio),
+		temas:     make(map[string]bool),
+	}
+
+	h.mu.Lock()
+	h.clientes[c] = true
+	conectados := len(h.clientes)
+	h.mu.Unlock()
+	log.Printf("Cliente WebSocket %s conectado (%d conectados)", p.Usuario, conectados)
+
+	go h.escribir(c)
+	h.leer(c)
+}
+
+// leer procesa las suscripciones del cliente hasta que se cierre la conexión
+func (h *Hub) leer(c *clienteWS) {
+	defer func() {
+		h.mu.Lock()
+		delete(h.clientes, c)
+		conectados := len(h.clientes)
+		h.mu.Unlock()
+		close(c.envio)
+		c.cerrar()
+		log.Printf("Cliente WebSocket %s desconectado (%d conectados)", c.principal.Usuario, conectados)
+	}()
+
+	// Sin mensajes ni pongs dentro de PongEspera la conexión se considera inactiva
+	c.conn.SetReadLimit(h.Config.MaxMensaje)
+	extenderPlazo := func() {
+		c.conn.SetReadDeadline(time.Now().Add(h.Config.PongEspera))
+	}
+	extenderPlazo()
+	c.conn.SetPongHandler(func(string) error {
+		extenderPlazo()
+		return nil
+	})
+
+	for {
+		var s solicitudWS
+		if err := c.conn.ReadJSON(&s); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Printf("Error al leer mensaje WebSocket: %v", err)
+			}
+			return
+		}
+		extenderPlazo()
+
+		respuesta := respuestaWS{Tema: s.Tema}
+		switch {
+		case s.Accion == "subscribe" && puedeSuscribirse(c.principal, s.Tema):
+			h.mu.Lock()
+			c.temas[s.Tema] = true
+			h.mu.Unlock()
+			respuesta.Tipo = "subscribed"
+		case s.Accion == "subscribe":
+			respuesta.Tipo, respuesta.Mensaje = "error", "No tiene permiso para suscribirse a este tema"
+		case s.Accion == "unsubscribe":
+			h.mu.Lock()
+			delete(c.temas, s.Tema)
+			h.mu.Unlock()
+			respuesta.Tipo = "unsubscribed"
+		default:
+			respuesta.Tipo, respuesta.Mensaje = "error", "Acción desconocida"
+		}
+
+		msg, _ := json.Marshal(respuesta)
+		h.mu.RLock()
+		h.encolar(c, msg)
+		h.mu.RUnlock()
+	}
+}
+
+// escribir envía al cliente los mensajes de su cola y los pings periódicos;
+// es el único que escribe mensajes en la conexión
+func (h *Hub) escribir(c *clienteWS) {
+	ticker := time.NewTicker(h.Config.PingIntervalo)
+	defer func() {
+		ticker.Stop()
+		c.cerrar()
+	}()
+
+	for {
+		select {
+		case msg, ok := <-c.envio:
+			c.conn.SetWriteDeadline(time.Now().Add(h.Config.EscrituraEspera))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				log.Printf("Error al enviar mensaje WebSocket: %v", err)
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(h.Config.EscrituraEspera))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
 }
@@ -2,6 +2,53 @@
 let currentStudentId = null
 const apiBaseUrl = "https://backendgo-production-46db.up.railway.app/api"
 let confirmModalCallback = null
+let authToken = localStorage.getItem("authToken")
+
+// Realizar una solicitud a la API con el token de sesión
+function apiFetch(url, options = {}) {
+  const headers = { ...(options.headers || {}) }
+  if (authToken) {
+    headers["Authorization"] = `Bearer ${authToken}`
+  }
+  return fetch(url, { ...options, headers })
+}
+
+// Construir un Error a partir del sobre JSON de error de la API ({code, message, details, request_id})
+async function apiError(response, fallback) {
+  let body = {}
+  try {
+    body = await response.json()
+  } catch (e) {
+    // Respuesta sin cuerpo JSON
+  }
+  const error = new Error(body.message || fallback)
+  error.code = body.code
+  error.details = body.details
+  return error
+}
+
+// Guardar la sesión devuelta por /auth/login
+function saveSession(session) {
+  authToken = session.token
+  localStorage.setItem("authToken", authToken)
+  currentStudentId = session.usuario.id_referencia
+  localStorage.setItem("currentStudentId", currentStudentId)
+}
+
+// Iniciar sesión con usuario y contraseña
+async function login(usuario, password) {
+  const response = await fetch(`${apiBaseUrl}/auth/login`, {
+    method: "POST",
+    headers: {
+      "Content-Type": "application/json",
+    },
+    body: JSON.stringify({ usuario, password }),
+  })
+  if (!response.ok) {
+    throw new Error("Usuario o contraseña incorrectos")
+  }
+  saveSession(await response.json())
+}
 
 // Elementos DOM
 const sections = {
@@ -37,6 +84,54 @@ document.addEventListener("DOMContentLoaded", () => {
   checkExistingStudent()
 })
 
+// Conexión WebSocket para recibir cambios en tiempo real
+let liveSocket = null
+
+// Conectar al WebSocket y suscribirse a los cambios del estudiante
+function connectLiveUpdates() {
+  if (!authToken || !currentStudentId || liveSocket) {
+    return
+  }
+
+  const wsUrl = apiBaseUrl.replace(/^http/, "ws") + `/ws?token=${encodeURIComponent(authToken)}`
+  liveSocket = new WebSocket(wsUrl)
+
+  liveSocket.onopen = () => {
+    liveSocket.send(JSON.stringify({ action: "subscribe", topic: `estudiante:${currentStudentId}` }))
+  }
+
+  liveSocket.onmessage = (event) => {
+    const message = JSON.parse(event.data)
+    if (message.type !== "change") {
+      return
+    }
+    // Recargar la sección visible afectada por el cambio
+    if (message.table === "registro_notas" && sections.grades.style.display !== "none") {
+      loadGrades()
+    } else if (message.table === "matriculas" && sections.enrollment.style.display !== "none") {
+      loadEnrollments()
+    } else if (message.table === "estudiantes" && sections.profile.style.display !== "none") {
+      loadStudentProfile()
+    }
+  }
+
+  liveSocket.onclose = () => {
+    liveSocket = null
+    // Reintentar mientras la sesión siga abierta
+    if (authToken) {
+      setTimeout(connectLiveUpdates, 5000)
+    }
+  }
+}
+
+// Cerrar la conexión WebSocket al cerrar sesión
+function disconnectLiveUpdates() {
+  if (liveSocket) {
+    liveSocket.close()
+    liveSocket = null
+  }
+}
+
 // Configurar event listeners
 function setupEventListeners() {
   // Navegación
@@ -44,9 +139,8 @@ function setupEventListeners() {
     navLinks[key].addEventListener("click", () => showSection(key))
   }
 
-  // Login y registro
+  // Login
   document.getElementById("btn-login").addEventListener("click", loginStudent)
-  document.getElementById("btn-register").addEventListener("click", registerStudent)
 
   // Botón de cerrar sesión (nuevo)
   const logoutBtn = document.createElement("button")
@@ -108,74 +202,42 @@ function showLoginSection() {
 // Iniciar sesión
 async function loginStudent() {
   const studentId = document.getElementById("login-id").value.trim()
-  if (!studentId) {
-    alert("Por favor ingresa tu ID de estudiante")
+  const password = document.getElementById("login-password").value
+  if (!studentId || !password) {
+    alert("Por favor ingresa tu ID de estudiante y tu contraseña")
     return
   }
 
   try {
-    const response = await fetch(`${apiBaseUrl}/estudiantes/${studentId}`)
-    if (!response.ok) {
-      throw new Error("Estudiante no encontrado")
-    }
+    await login(studentId, password)
 
-    const student = await response.json()
-    currentStudentId = student.id_estudiantes
-    localStorage.setItem("currentStudentId", currentStudentId)
-    
     updateUIForLoggedInUser()
     showSection("home")
   } catch (error) {
     console.error("Error:", error)
-    alert("ID de estudiante no válido. Por favor verifica e intenta nuevamente.")
+    alert("ID de estudiante o contraseña incorrectos. Por favor verifica e intenta nuevamente.")
   }
 }
 
-// Registrar nuevo estudiante
-async function registerStudent() {
-  const nombre = document.getElementById("register-name").value.trim()
-  if (!nombre) {
-    alert("Por favor ingresa tu nombre completo")
-    return
-  }
-
+// Cerrar sesión
+async function logoutStudent() {
   try {
-    const response = await fetch(`${apiBaseUrl}/estudiantes`, {
-      method: "POST",
-      headers: {
-        "Content-Type": "application/json",
-      },
-      body: JSON.stringify({ nombre }),
-    })
-
-    if (!response.ok) {
-      throw new Error("Error al registrar estudiante")
-    }
-
-    const newStudent = await response.json()
-    alert(`Registro exitoso! Tu ID de estudiante es: ${newStudent.id_estudiantes}\nGuarda este ID para iniciar sesión en el futuro.`)
-    
-    // Iniciar sesión automáticamente con el nuevo estudiante
-    currentStudentId = newStudent.id_estudiantes
-    localStorage.setItem("currentStudentId", currentStudentId)
-    
-    updateUIForLoggedInUser()
-    showSection("home")
+    await apiFetch(`${apiBaseUrl}/auth/logout`, { method: "POST" })
   } catch (error) {
     console.error("Error:", error)
-    alert("No se pudo completar el registro. Por favor intenta nuevamente.")
   }
-}
-
-// Cerrar sesión
-function logoutStudent() {
+  localStorage.removeItem("authToken")
   localStorage.removeItem("currentStudentId")
+  authToken = null
   currentStudentId = null
+  disconnectLiveUpdates()
   showLoginSection()
 }
 
 // Actualizar UI para usuario con sesión iniciada
 function updateUIForLoggedInUser() {
+  connectLiveUpdates()
+
   // Mostrar la barra lateral
   document.querySelector(".sidebar").style.display = "block"
   document.querySelector(".content").classList.add("col-md-9", "col-lg-10")
@@ -194,7 +256,7 @@ async function saveProfile(event) {
 
   try {
     // Solo actualizar estudiante existente (ya no creamos nuevos aquí)
-    const response = await fetch(`${apiBaseUrl}/estudiantes/${currentStudentId}`, {
+    const response = await apiFetch(`${apiBaseUrl}/estudiantes/${currentStudentId}`, {
       method: "PUT",
       headers: {
         "Content-Type": "application/json",
@@ -248,6 +310,7 @@ function checkExistingStudent() {
   if (savedStudentId) {
     currentStudentId = savedStudentId
     loadStudentProfile()
+    connectLiveUpdates()
   } else {
     // Si no hay estudiante, mostrar formulario de creación
     showCreateStudentForm()
@@ -267,7 +330,7 @@ async function loadStudentProfile() {
   if (!currentStudentId) return
 
   try {
-    const response = await fetch(`${apiBaseUrl}/estudiantes/${currentStudentId}`)
+    const response = await apiFetch(`${apiBaseUrl}/estudiantes/${currentStudentId}`)
     if (!response.ok) {
       throw new Error("Error al cargar el perfil")
     }
@@ -318,7 +381,7 @@ async function saveProfile(event) {
   try {
     if (currentStudentId) {
       // Actualizar estudiante existente
-      const response = await fetch(`${apiBaseUrl}/estudiantes/${currentStudentId}`, {
+      const response = await apiFetch(`${apiBaseUrl}/estudiantes/${currentStudentId}`, {
         method: "PUT",
         headers: {
           "Content-Type": "application/json",
@@ -333,7 +396,7 @@ async function saveProfile(event) {
       alert("Perfil actualizado correctamente")
     } else {
       // Crear nuevo estudiante
-      const response = await fetch(`${apiBaseUrl}/estudiantes`, {
+      const response = await apiFetch(`${apiBaseUrl}/estudiantes`, {
         method: "POST",
         headers: {
           "Content-Type": "application/json",
@@ -363,17 +426,28 @@ async function saveProfile(event) {
 // Cargar asignaturas disponibles
 async function loadAvailableSubjects() {
   try {
-    const response = await fetch(`${apiBaseUrl}/asignaturas-disponibles`)
+    // Con un perfil de estudiante solo se ofrecen las asignaciones en las que puede matricularse
+    const url = currentStudentId
+      ? `${apiBaseUrl}/estudiantes/${currentStudentId}/asignaturas-disponibles`
+      : `${apiBaseUrl}/asignaturas-disponibles`
+    const response = await apiFetch(url)
     if (!response.ok) {
-      throw new Error("Error al cargar asignaturas")
+      throw await apiError(response, "Error al cargar asignaturas")
     }
 
-    const subjects = await response.json()
+    const subjects = (await response.json()).map((subject) => ({
+      id: subject.id_profesores_ciclos_asignaturas || subject.id,
+      asignatura: subject.nombre_asignatura || subject.asignatura,
+      profesor: subject.nombre_profesor || subject.profesor,
+      ciclo: subject.ciclo,
+      creditos: subject.creditos ?? "",
+      cupos: subject.cupos_disponibles ?? null,
+    }))
     const tableBody = document.getElementById("subjects-table-body")
     tableBody.innerHTML = ""
 
     if (subjects.length === 0) {
-      tableBody.innerHTML = '<tr><td colspan="4" class="text-center">No hay asignaturas disponibles</td></tr>'
+      tableBody.innerHTML = '<tr><td colspan="6" class="text-center">No hay asignaturas disponibles</td></tr>'
       return
     }
 
@@ -383,6 +457,8 @@ async function loadAvailableSubjects() {
                 <td>${subject.asignatura}</td>
                 <td>${subject.profesor}</td>
                 <td>${subject.ciclo}</td>
+                <td>${subject.creditos}</td>
+                <td>${subject.cupos === null ? "Sin límite" : subject.cupos}</td>
                 <td>
                     <button class="btn btn-sm btn-primary enroll-btn" data-id="${subject.id}">
                         Matricular
@@ -415,7 +491,7 @@ async function loadAvailableSubjects() {
 // Matricular en asignatura
 async function enrollInSubject(asignacionId) {
   try {
-    const response = await fetch(`${apiBaseUrl}/matriculas`, {
+    const response = await apiFetch(`${apiBaseUrl}/matriculas`, {
       method: "POST",
       headers: {
         "Content-Type": "application/json",
@@ -427,8 +503,7 @@ async function enrollInSubject(asignacionId) {
     })
 
     if (!response.ok) {
-      const errorData = await response.json()
-      throw new Error(errorData.error || "Error al matricularse")
+      throw await apiError(response, "Error al matricularse")
     }
 
     alert("Te has matriculado correctamente")
@@ -444,13 +519,12 @@ async function loadEnrollments() {
   if (!currentStudentId) return
 
   try {
-    const response = await fetch(`${apiBaseUrl}/matriculas`)
+    const response = await apiFetch(`${apiBaseUrl}/estudiantes/${currentStudentId}/matriculas`)
     if (!response.ok) {
       throw new Error("Error al cargar matrículas")
     }
 
-    const allEnrollments = await response.json()
-    const enrollments = allEnrollments.filter((e) => e.id_estudiantes === currentStudentId)
+    const enrollments = await response.json()
 
     const tableBody = document.getElementById("enrollments-table-body")
     tableBody.innerHTML = ""
@@ -494,7 +568,7 @@ async function loadEnrollments() {
 // Anular matrícula
 async function unenrollFromSubject(matriculaId) {
   try {
-    const response = await fetch(`${apiBaseUrl}/matriculas/${matriculaId}`, {
+    const response = await apiFetch(`${apiBaseUrl}/matriculas/${matriculaId}`, {
       method: "DELETE",
     })
 
@@ -515,7 +589,7 @@ async function loadGrades() {
   if (!currentStudentId) return
 
   try {
-    const response = await fetch(`${apiBaseUrl}/notas-estudiante/${currentStudentId}`)
+    const response = await apiFetch(`${apiBaseUrl}/notas-estudiante/${currentStudentId}`)
     if (!response.ok) {
       throw new Error("Error al cargar calificaciones")
     }
@@ -530,9 +604,13 @@ async function loadGrades() {
     }
 
     grades.forEach((grade) => {
-      const promedio = (grade.nota1 + grade.nota2) / 2
-      const estado = grade.sup === 1 ? "Suplementario" : "Aprobado"
-      const estadoClass = grade.sup === 1 ? "text-danger" : "text-success"
+      const estados = {
+        aprobado: { texto: "Aprobado", clase: "text-success" },
+        reprobado: { texto: "Reprobado", clase: "text-danger" },
+        supletorio: { texto: "Supletorio", clase: "text-warning" },
+        en_curso: { texto: "En curso", clase: "text-muted" },
+      }
+      const estado = estados[grade.estado] || { texto: grade.estado, clase: "" }
 
       const row = document.createElement("tr")
       row.innerHTML = `
@@ -540,8 +618,8 @@ async function loadGrades() {
                 <td>${grade.nombre_profesor}</td>
                 <td>${grade.nota1.toFixed(2)}</td>
                 <td>${grade.nota2.toFixed(2)}</td>
-                <td>${promedio.toFixed(2)}</td>
-                <td class="${estadoClass}">${estado}</td>
+                <td>${grade.nota_final.toFixed(2)}</td>
+                <td class="${estado.clase}">${estado.texto}</td>
             `
       tableBody.appendChild(row)
     })
@@ -0,0 +1,82 @@
+package kardex
+
+import (
+	"encoding/csv"
+	"io"
+	"server_estudiantes/models"
+	"strconv"
+)
+
+// Valores de la columna tipo del CSV
+const (
+	filaAsignatura = "asignatura"
+	filaCiclo      = "ciclo"
+	filaTotal      = "total"
+)
+
+// columnasCSV es el encabezado de la única tabla del CSV; cada fila llena las columnas
+// que corresponden a su tipo y deja vacías las demás
+var columnasCSV = []string{
+	"tipo", "ciclo", "asignatura", "profesor", "creditos", "nota1", "nota2", "sup", "nota_final", "estado",
+	"creditos_inscritos", "creditos_aprobados", "promedio", "creditos_acumulados", "promedio_acumulado",
+}
+
+// EscribirCSV escribe el kardex como una sola tabla: una fila por asignatura cursada,
+// seguida del resumen de su ciclo, y al final una fila con el total
+func EscribirCSV(w io.Writer, k models.Kardex) error {
+	cw := csv.NewWriter(w)
+
+	cw.Write(columnasCSV)
+	for _, c := range k.Ciclos {
+		for _, a := range c.Asignaturas {
+			cw.Write([]string{
+				filaAsignatura,
+				c.Ciclo,
+				a.NombreAsignatura,
+				a.NombreProfesor,
+				strconv.Itoa(a.Creditos),
+				decimal(a.Nota1),
+				decimal(a.Nota2),
+				strconv.Itoa(a.Sup),
+				decimal(a.NotaFinal),
+				a.Estado,
+				"", "", "", "", "",
+			})
+		}
+		cw.Write([]string{
+			filaCiclo,
+			c.Ciclo,
+			"", "", "", "", "", "", "", "",
+			strconv.Itoa(c.CreditosInscritos),
+			strconv.Itoa(c.CreditosAprobados),
+			decimalOpcional(c.Promedio),
+			strconv.Itoa(c.CreditosAcumulados),
+			decimalOpcional(c.PromedioAcumulado),
+		})
+	}
+	cw.Write([]string{
+		filaTotal,
+		"", "", "", "", "", "", "", "", "",
+		strconv.Itoa(k.CreditosInscritos),
+		strconv.Itoa(k.CreditosAprobados),
+		decimalOpcional(k.PromedioGeneral),
+		strconv.Itoa(k.CreditosAprobados),
+		decimalOpcional(k.PromedioGeneral),
+	})
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// decimal formatea una nota con dos decimales
+func decimal(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
+
+// decimalOpcional formatea un promedio que puede no existir
+func decimalOpcional(v *float64) string {
+	if v == nil {
+		return ""
+	}
+	return decimal(*v)
+}
@@ -0,0 +1,69 @@
+package kardex
+
+import (
+	"bytes"
+	"encoding/csv"
+	"server_estudiantes/models"
+	"strings"
+	"testing"
+)
+
+func TestEscribirCSV(t *testing.T) {
+	encabezado := "tipo,ciclo,asignatura,profesor,creditos,nota1,nota2,sup,nota_final,estado," +
+		"creditos_inscritos,creditos_aprobados,promedio,creditos_acumulados,promedio_acumulado\n"
+
+	casos := []struct {
+		nombre string
+		k      models.Kardex
+		csv    string
+	}{
+		{
+			nombre: "sin matrículas",
+			k:      models.Kardex{},
+			csv:    encabezado + "total,,,,,,,,,,0,0,,0,\n",
+		},
+		{
+			nombre: "un ciclo",
+			k: models.Kardex{
+				Ciclos: []models.KardexCiclo{{
+					Ciclo: "2024-1",
+					Asignaturas: []models.KardexAsignatura{
+						{NombreAsignatura: "Álgebra", NombreProfesor: "Pérez, Juan", Creditos: 4, Nota1: 8, Nota2: 9, NotaFinal: 8.5, Estado: models.KardexAprobada},
+						{NombreAsignatura: "Física", NombreProfesor: "Ríos", Creditos: 3, Estado: models.KardexEnCurso},
+					},
+					Promedio:           decimalPtr(8.5),
+					CreditosInscritos:  7,
+					CreditosAprobados:  4,
+					PromedioAcumulado:  decimalPtr(8.5),
+					CreditosAcumulados: 4,
+				}},
+				PromedioGeneral:   decimalPtr(8.5),
+				CreditosInscritos: 7,
+				CreditosAprobados: 4,
+			},
+			csv: encabezado +
+				"asignatura,2024-1,Álgebra,\"Pérez, Juan\",4,8.00,9.00,0,8.50,aprobada,,,,,\n" +
+				"asignatura,2024-1,Física,Ríos,3,0.00,0.00,0,0.00,en_curso,,,,,\n" +
+				"ciclo,2024-1,,,,,,,,,7,4,8.50,4,8.50\n" +
+				"total,,,,,,,,,,7,4,8.50,4,8.50\n",
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := EscribirCSV(&b, c.k); err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != c.csv {
+				t.Errorf("CSV =\n%s\nse esperaba\n%s", b.String(), c.csv)
+			}
+			// Es una sola tabla: todas las filas tienen las columnas del encabezado
+			r := csv.NewReader(strings.NewReader(b.String()))
+			r.FieldsPerRecord = len(columnasCSV)
+			if _, err := r.ReadAll(); err != nil {
+				t.Errorf("el CSV no es una tabla uniforme: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+// Package kardex arma el historial académico de un estudiante a partir de sus registros
+// de notas y lo presenta en CSV o en un PDF imprimible.
+package kardex
+
+import (
+	"math"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+	"sort"
+	"time"
+)
+
+// Construir agrupa por ciclo los registros de notas del estudiante, evaluados con la
+// política de calificación, y calcula los promedios y créditos de cada ciclo y acumulados
+func Construir(e models.Estudiante, notas []models.Nota, politica config.PoliticaCalificacion, generado time.Time) models.Kardex {
+	k := models.Kardex{
+		IDEstudiante:     e.IDEstudiante,
+		NombreEstudiante: e.Nombre,
+		Ciclos:           []models.KardexCiclo{},
+		Generado:         generado,
+	}
+
+	ordenadas := append([]models.Nota(nil), notas...)
+	sort.SliceStable(ordenadas, func(i, j int) bool {
+		a, b := ordenadas[i], ordenadas[j]
+		if a.Ciclo != b.Ciclo {
+			return a.Ciclo < b.Ciclo
+		}
+		if a.IDCiclo != b.IDCiclo {
+			return a.IDCiclo < b.IDCiclo
+		}
+		return a.NombreAsignatura < b.NombreAsignatura
+	})
+
+	var delCiclo, acumulado promedio
+	for _, n := range ordenadas {
+		if len(k.Ciclos) == 0 || k.Ciclos[len(k.Ciclos)-1].IDCiclo != n.IDCiclo {
+			k.Ciclos = append(k.Ciclos, models.KardexCiclo{
+				IDCiclo:     n.IDCiclo,
+				Ciclo:       n.Ciclo,
+				Asignaturas: []models.KardexAsignatura{},
+			})
+			delCiclo = promedio{}
+		}
+		c := &k.Ciclos[len(k.Ciclos)-1]
+
+		a := asignatura(n, politica)
+		c.Asignaturas = append(c.Asignaturas, a)
+		c.CreditosInscritos += a.Creditos
+		k.CreditosInscritos += a.Creditos
+		if a.Estado == models.KardexAprobada {
+			c.CreditosAprobados += a.Creditos
+			k.CreditosAprobados += a.Creditos
+		}
+		if a.Estado != models.KardexEnCurso {
+			delCiclo.agregar(a.NotaFinal, a.Creditos)
+			acumulado.agregar(a.NotaFinal, a.Creditos)
+		}
+
+		c.Promedio = delCiclo.valor()
+		c.PromedioAcumulado = acumulado.valor()
+		c.CreditosAcumulados = k.CreditosAprobados
+	}
+
+	k.PromedioGeneral = acumulado.valor()
+	return k
+}
+
+// asignatura evalúa un registro de notas; un registro sin calificar o con el supletorio
+// pendiente sigue en curso
+func asignatura(n models.Nota, politica config.PoliticaCalificacion) models.KardexAsignatura {
+	_, notaFinal, estado := politica.Evaluar(n.Calificado, n.Nota1, n.Nota2, n.Sup)
+
+	a := models.KardexAsignatura{
+		IDMatricula:      n.IDMatricula,
+		IDAsignatura:     n.IDAsignatura,
+		NombreAsignatura: n.NombreAsignatura,
+		NombreProfesor:   n.NombreProfesor,
+		Creditos:         n.Creditos,
+		Nota1:            n.Nota1,
+		Nota2:            n.Nota2,
+		Sup:              n.Sup,
+		NotaFinal:        notaFinal,
+	}
+	switch {
+	case !config.Finalizado(estado):
+		a.Estado = models.KardexEnCurso
+	case estado == config.EstadoAprobado:
+		a.Estado = models.KardexAprobada
+	default:
+		a.Estado = models.KardexReprobada
+	}
+	return a
+}
+
+// promedio acumula un promedio ponderado por créditos
+type promedio struct {
+	suma     float64
+	creditos int
+}
+
+func (p *promedio) agregar(nota float64, creditos int) {
+	p.suma += nota * float64(creditos)
+	p.creditos += creditos
+}
+
+// valor devuelve el promedio redondeado a dos decimales, o nil si no hay créditos
+func (p promedio) valor() *float64 {
+	if p.creditos == 0 {
+		return nil
+	}
+	v := math.Round(p.suma/float64(p.creditos)*100) / 100
+	return &v
+}
@@ -0,0 +1,150 @@
+package kardex
+
+import (
+	"reflect"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+	"testing"
+	"time"
+)
+
+var politicaPrueba = config.PoliticaCalificacion{
+	NotaMaxima:           10,
+	Peso1:                0.5,
+	Peso2:                0.5,
+	NotaAprobacion:       7,
+	NotaMinimaSupletorio: 5,
+	ModoSupletorio:       config.SupletorioReemplaza,
+}
+
+func nota(idCiclo, ciclo, asignatura string, creditos int, calificado bool, nota1, nota2 float64, sup int) models.Nota {
+	return models.Nota{
+		IDCiclo:          idCiclo,
+		Ciclo:            ciclo,
+		IDAsignatura:     asignatura,
+		NombreAsignatura: asignatura,
+		Creditos:         creditos,
+		Nota1:            nota1,
+		Nota2:            nota2,
+		Sup:              sup,
+		Calificado:       calificado,
+	}
+}
+
+func decimalPtr(v float64) *float64 {
+	return &v
+}
+
+func TestConstruir(t *testing.T) {
+	estudiante := models.Estudiante{IDEstudiante: "0123456789abcdef0123", Nombre: "Ana"}
+	generado := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+
+	// Los registros llegan desordenados; el kardex los agrupa por ciclo y asignatura
+	notas := []models.Nota{
+		nota("c2", "2024-2", "Química", 3, true, 9, 9, 0),
+		nota("c1", "2024-1", "Historia", 2, true, 3, 4, 0),
+		nota("c2", "2024-2", "Cálculo", 4, true, 6, 5, 0),
+		nota("c1", "2024-1", "Álgebra", 4, true, 8, 9, 0),
+		nota("c2", "2024-2", "Física", 3, false, 0, 0, 0),
+	}
+
+	k := Construir(estudiante, notas, politicaPrueba, generado)
+
+	type resumen struct {
+		ciclo              string
+		asignaturas        []string
+		estados            []string
+		promedio           *float64
+		inscritos          int
+		aprobados          int
+		promedioAcumulado  *float64
+		creditosAcumulados int
+	}
+	esperados := []resumen{
+		{
+			ciclo:       "2024-1",
+			asignaturas: []string{"Historia", "Álgebra"},
+			estados:     []string{models.KardexReprobada, models.KardexAprobada},
+			// (3.5*2 + 8.5*4) / 6
+			promedio:           decimalPtr(6.83),
+			inscritos:          6,
+			aprobados:          4,
+			promedioAcumulado:  decimalPtr(6.83),
+			creditosAcumulados: 4,
+		},
+		{
+			ciclo:       "2024-2",
+			asignaturas: []string{"Cálculo", "Física", "Química"},
+			// El supletorio pendiente y el registro sin calificar siguen en curso y no promedian
+			estados:   []string{models.KardexEnCurso, models.KardexEnCurso, models.KardexAprobada},
+			promedio:  decimalPtr(9),
+			inscritos: 10,
+			aprobados: 3,
+			// (3.5*2 + 8.5*4 + 9*3) / 9
+			promedioAcumulado:  decimalPtr(7.56),
+			creditosAcumulados: 7,
+		},
+	}
+
+	if len(k.Ciclos) != len(esperados) {
+		t.Fatalf("ciclos = %d, se esperaba %d", len(k.Ciclos), len(esperados))
+	}
+	for i, e := range esperados {
+		c := k.Ciclos[i]
+		var asignaturas, estados []string
+		for _, a := range c.Asignaturas {
+			asignaturas = append(asignaturas, a.NombreAsignatura)
+			estados = append(estados, a.Estado)
+		}
+		got := resumen{c.Ciclo, asignaturas, estados, c.Promedio, c.CreditosInscritos, c.CreditosAprobados, c.PromedioAcumulado, c.CreditosAcumulados}
+		if !reflect.DeepEqual(got, e) {
+			t.Errorf("ciclo %d = %+v, se esperaba %+v", i, got, e)
+		}
+	}
+
+	if k.CreditosInscritos != 16 || k.CreditosAprobados != 7 || !reflect.DeepEqual(k.PromedioGeneral, decimalPtr(7.56)) {
+		t.Errorf("totales = %d inscritos, %d aprobados, promedio %v", k.CreditosInscritos, k.CreditosAprobados, k.PromedioGeneral)
+	}
+	if k.IDEstudiante != estudiante.IDEstudiante || k.NombreEstudiante != estudiante.Nombre || !k.Generado.Equal(generado) {
+		t.Errorf("encabezado = %s %s %v", k.IDEstudiante, k.NombreEstudiante, k.Generado)
+	}
+}
+
+func TestConstruirEstados(t *testing.T) {
+	casos := []struct {
+		nombre       string
+		calificado   bool
+		nota1, nota2 float64
+		sup          int
+		notaFinal    float64
+		estado       string
+	}{
+		{"sin calificar", false, 0, 0, 0, 0, models.KardexEnCurso},
+		{"calificada con cero", true, 0, 0, 0, 0, models.KardexReprobada},
+		{"supletorio pendiente", true, 6, 5, 0, 5.5, models.KardexEnCurso},
+		{"aprobada con supletorio", true, 6, 5, 8, 8, models.KardexAprobada},
+		{"reprobada con supletorio", true, 6, 5, 6, 6, models.KardexReprobada},
+		{"aprobada", true, 7, 8, 0, 7.5, models.KardexAprobada},
+		{"reprobada", true, 2, 3, 0, 2.5, models.KardexReprobada},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			k := Construir(models.Estudiante{}, []models.Nota{nota("c1", "2024-1", "Álgebra", 4, c.calificado, c.nota1, c.nota2, c.sup)}, politicaPrueba, time.Time{})
+			a := k.Ciclos[0].Asignaturas[0]
+			if a.NotaFinal != c.notaFinal || a.Estado != c.estado {
+				t.Errorf("asignatura = (%g, %s), se esperaba (%g, %s)", a.NotaFinal, a.Estado, c.notaFinal, c.estado)
+			}
+			if finalizada := c.estado != models.KardexEnCurso; (k.PromedioGeneral != nil) != finalizada {
+				t.Errorf("promedio general = %v con estado %s", k.PromedioGeneral, c.estado)
+			}
+		})
+	}
+}
+
+func TestConstruirSinNotas(t *testing.T) {
+	k := Construir(models.Estudiante{}, nil, politicaPrueba, time.Time{})
+	if k.Ciclos == nil || len(k.Ciclos) != 0 || k.PromedioGeneral != nil || k.CreditosInscritos != 0 {
+		t.Errorf("kardex vacío = %+v", k)
+	}
+}
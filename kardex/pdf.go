@@ -0,0 +1,229 @@
+package kardex
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"server_estudiantes/models"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Medidas de la página A4 en puntos y del texto; se usa Courier para que las columnas
+// queden alineadas sin medir el ancho de cada carácter
+const (
+	anchoPagina     = 595
+	altoPagina      = 842
+	margen          = 50
+	tamanoFuente    = 8
+	altoLinea       = 11
+	lineasPorPagina = (altoPagina - 2*margen) / altoLinea
+)
+
+// Anchos en caracteres de las columnas de asignaturas
+var columnas = []struct {
+	titulo string
+	ancho  int
+}{
+	{"Asignatura", 30},
+	{"Profesor", 22},
+	{"Créd.", 5},
+	{"Nota 1", 6},
+	{"Nota 2", 6},
+	{"Sup.", 4},
+	{"Final", 6},
+	{"Estado", 10},
+}
+
+// etiquetasEstado son los estados tal como se imprimen
+var etiquetasEstado = map[string]string{
+	models.KardexAprobada:  "Aprobada",
+	models.KardexReprobada: "Reprobada",
+	models.KardexEnCurso:   "En curso",
+}
+
+// linea es una línea de texto del documento; negrita usa Courier-Bold
+type linea struct {
+	texto   string
+	negrita bool
+}
+
+// EscribirPDF escribe el kardex como un documento PDF de una o más páginas
+func EscribirPDF(w io.Writer, k models.Kardex) error {
+	lineas := lineasKardex(k)
+
+	var paginas [][]linea
+	for len(lineas) > lineasPorPagina {
+		paginas = append(paginas, lineas[:lineasPorPagina])
+		lineas = lineas[lineasPorPagina:]
+	}
+	paginas = append(paginas, lineas)
+
+	_, err := w.Write(documento(paginas))
+	return err
+}
+
+// lineasKardex arma el texto del documento
+func lineasKardex(k models.Kardex) []linea {
+	lineas := []linea{
+		{texto: "KARDEX ACADÉMICO", negrita: true},
+		{},
+		{texto: "Estudiante: " + k.NombreEstudiante},
+		{texto: "ID: " + k.IDEstudiante},
+		{texto: "Generado: " + k.Generado.Format("2006-01-02 15:04")},
+		{},
+	}
+
+	encabezado := make([]string, len(columnas))
+	for i, c := range columnas {
+		encabezado[i] = c.titulo
+	}
+
+	for _, c := range k.Ciclos {
+		lineas = append(lineas,
+			linea{texto: "Ciclo " + c.Ciclo, negrita: true},
+			linea{texto: fila(encabezado), negrita: true},
+		)
+		for _, a := range c.Asignaturas {
+			lineas = append(lineas, linea{texto: fila([]string{
+				a.NombreAsignatura,
+				a.NombreProfesor,
+				strconv.Itoa(a.Creditos),
+				decimal(a.Nota1),
+				decimal(a.Nota2),
+				strconv.Itoa(a.Sup),
+				decimal(a.NotaFinal),
+				etiquetasEstado[a.Estado],
+			})})
+		}
+		lineas = append(lineas,
+			linea{texto: fmt.Sprintf("Créditos aprobados: %d de %d   Promedio del ciclo: %s",
+				c.CreditosAprobados, c.CreditosInscritos, promedioImpreso(c.Promedio))},
+			linea{texto: fmt.Sprintf("Créditos acumulados: %d   Promedio acumulado: %s",
+				c.CreditosAcumulados, promedioImpreso(c.PromedioAcumulado))},
+			linea{},
+		)
+	}
+
+	if len(k.Ciclos) == 0 {
+		lineas = append(lineas, linea{texto: "El estudiante no tiene matrículas registradas."}, linea{})
+	}
+	lineas = append(lineas,
+		linea{texto: fmt.Sprintf("TOTAL   Créditos aprobados: %d de %d   Promedio general: %s",
+			k.CreditosAprobados, k.CreditosInscritos, promedioImpreso(k.PromedioGeneral)), negrita: true},
+	)
+	return lineas
+}
+
+// fila ajusta cada valor al ancho de su columna
+func fila(valores []string) string {
+	var b strings.Builder
+	for i, v := range valores {
+		ancho := columnas[i].ancho
+		if utf8.RuneCountInString(v) > ancho {
+			v = string([]rune(v)[:ancho-1]) + "."
+		}
+		b.WriteString(v)
+		if i < len(valores)-1 {
+			b.WriteString(strings.Repeat(" ", ancho-utf8.RuneCountInString(v)+1))
+		}
+	}
+	return b.String()
+}
+
+// promedioImpreso muestra un guion cuando no hay asignaturas finalizadas
+func promedioImpreso(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return decimal(*v)
+}
+
+// documento genera los bytes del PDF: catálogo, árbol de páginas, las dos fuentes
+// estándar y una página con su contenido por cada grupo de líneas
+func documento(paginas [][]linea) []byte {
+	var objetos []string
+	agregar := func(contenido string) int {
+		objetos = append(objetos, contenido)
+		return len(objetos)
+	}
+
+	catalogo := agregar("")
+	arbol := agregar("")
+	normal := agregar("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	negrita := agregar("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>")
+
+	var hijos []string
+	for i, lineas := range paginas {
+		flujo := contenidoPagina(lineas, i+1, len(paginas))
+		contenido := agregar(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(flujo), flujo))
+		pagina := agregar(fmt.Sprintf(
+			"<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> /Contents %d 0 R >>",
+			arbol, anchoPagina, altoPagina, normal, negrita, contenido))
+		hijos = append(hijos, fmt.Sprintf("%d 0 R", pagina))
+	}
+	objetos[catalogo-1] = fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", arbol)
+	objetos[arbol-1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(hijos, " "), len(hijos))
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	desplazamientos := make([]int, len(objetos))
+	for i, o := range objetos {
+		desplazamientos[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+
+	inicioXref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objetos)+1)
+	for _, d := range desplazamientos {
+		fmt.Fprintf(&b, "%010d 00000 n \n", d)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objetos)+1, catalogo, inicioXref)
+	return b.Bytes()
+}
+
+// contenidoPagina escribe las líneas de una página de arriba hacia abajo y su número al pie
+func contenidoPagina(lineas []linea, numero, total int) string {
+	var b strings.Builder
+	b.WriteString("BT\n")
+	fmt.Fprintf(&b, "%d TL\n%d %d Td\n", altoLinea, margen, altoPagina-margen)
+	fuente := ""
+	for _, l := range lineas {
+		actual := "/F1"
+		if l.negrita {
+			actual = "/F2"
+		}
+		if actual != fuente {
+			fmt.Fprintf(&b, "%s %d Tf\n", actual, tamanoFuente)
+			fuente = actual
+		}
+		fmt.Fprintf(&b, "(%s) Tj T*\n", textoPDF(l.texto))
+	}
+	b.WriteString("ET\n")
+	fmt.Fprintf(&b, "BT /F1 %d Tf %d %d Td (%s) Tj ET", tamanoFuente, anchoPagina-margen-60, margen/2,
+		textoPDF(fmt.Sprintf("Página %d de %d", numero, total)))
+	return b.String()
+}
+
+// textoPDF convierte el texto a WinAnsiEncoding y escapa los caracteres especiales de
+// las cadenas PDF; los caracteres fuera de Latin-1 se reemplazan por '?'
+func textoPDF(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,120 @@
+package kardex
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"server_estudiantes/models"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestEscribirPDF(t *testing.T) {
+	asignaturas := func(n int) []models.KardexAsignatura {
+		var lista []models.KardexAsignatura
+		for i := 0; i < n; i++ {
+			lista = append(lista, models.KardexAsignatura{NombreAsignatura: fmt.Sprintf("Asignatura %d", i), Creditos: 3, Estado: models.KardexEnCurso})
+		}
+		return lista
+	}
+
+	casos := []struct {
+		nombre  string
+		k       models.Kardex
+		paginas int
+	}{
+		{"sin matrículas", models.Kardex{NombreEstudiante: "Ana"}, 1},
+		{"un ciclo", models.Kardex{NombreEstudiante: "Ana (repitente)", Ciclos: []models.KardexCiclo{{Ciclo: "2024-1", Asignaturas: asignaturas(5)}}}, 1},
+		{"varias páginas", models.Kardex{NombreEstudiante: "Ana", Ciclos: []models.KardexCiclo{{Ciclo: "2024-1", Asignaturas: asignaturas(150)}}}, 3},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			c.k.Generado = time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+			var b bytes.Buffer
+			if err := EscribirPDF(&b, c.k); err != nil {
+				t.Fatal(err)
+			}
+			pdf := b.Bytes()
+
+			if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+				t.Fatal("el documento no empieza con la cabecera PDF o no termina con el marcador EOF")
+			}
+			verificarXref(t, pdf)
+			verificarLongitudes(t, pdf)
+
+			if n := bytes.Count(pdf, []byte("/Type /Page ")); n != c.paginas {
+				t.Errorf("páginas = %d, se esperaban %d", n, c.paginas)
+			}
+			if !bytes.Contains(pdf, []byte(fmt.Sprintf("/Count %d", c.paginas))) {
+				t.Errorf("el árbol de páginas no declara /Count %d", c.paginas)
+			}
+			for i := 1; i <= c.paginas; i++ {
+				pie := textoPDF(fmt.Sprintf("Página %d de %d", i, c.paginas))
+				if !bytes.Contains(pdf, []byte("("+pie+")")) {
+					t.Errorf("falta el pie %q", pie)
+				}
+			}
+		})
+	}
+}
+
+// verificarXref comprueba que startxref apunte a la tabla xref y que cada entrada apunte
+// al inicio de su objeto
+func verificarXref(t *testing.T, pdf []byte) {
+	t.Helper()
+	m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+	if m == nil {
+		t.Fatal("falta startxref")
+	}
+	inicio, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(pdf[inicio:], []byte("xref\n")) {
+		t.Fatalf("startxref %d no apunta a la tabla xref", inicio)
+	}
+
+	entradas := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[inicio:], -1)
+	if len(entradas) == 0 {
+		t.Fatal("la tabla xref no tiene entradas")
+	}
+	for i, e := range entradas {
+		desplazamiento, _ := strconv.Atoi(string(e[1]))
+		if objeto := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[desplazamiento:], []byte(objeto)) {
+			t.Errorf("la entrada xref del objeto %d apunta a %d", i+1, desplazamiento)
+		}
+	}
+}
+
+// verificarLongitudes comprueba que /Length coincida con los bytes de cada stream
+func verificarLongitudes(t *testing.T, pdf []byte) {
+	t.Helper()
+	flujos := regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*?)\nendstream`).FindAllSubmatch(pdf, -1)
+	if len(flujos) == 0 {
+		t.Fatal("el documento no tiene streams")
+	}
+	for _, f := range flujos {
+		if largo, _ := strconv.Atoi(string(f[1])); largo != len(f[2]) {
+			t.Errorf("/Length %d, el stream tiene %d bytes", largo, len(f[2]))
+		}
+	}
+}
+
+func TestTextoPDF(t *testing.T) {
+	casos := []struct {
+		texto    string
+		esperado string
+	}{
+		{"Kardex", "Kardex"},
+		{"Ana (repitente)", `Ana \(repitente\)`},
+		{`C:\notas`, `C:\\notas`},
+		{"Página", `P\341gina`},
+		{"línea\nnueva", "l\\355nea nueva"},
+		{"€ 5", "? 5"},
+	}
+
+	for _, c := range casos {
+		if got := textoPDF(c.texto); got != c.esperado {
+			t.Errorf("textoPDF(%q) = %q, se esperaba %q", c.texto, got, c.esperado)
+		}
+	}
+}
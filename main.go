@@ -1,17 +1,62 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"server_estudiantes/auth"
 	"server_estudiantes/config"
 	"server_estudiantes/controllers"
 	"server_estudiantes/middleware"
+	"server_estudiantes/migraciones"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
 	"server_estudiantes/routes"
+	"sync"
+	"syscall"
 
 	"github.com/joho/godotenv"
 )
 
+// crearAdministrador registra el usuario definido en ADMIN_USUARIO y ADMIN_PASSWORD si aún
+// no existe. Sin ninguna de las dos variables no hace nada; una contraseña ausente, corta
+// o con el valor de ejemplo detiene el arranque en lugar de crear un acceso conocido
+func crearAdministrador(usuarios repository.UsuarioRepo) error {
+	usuario, password := os.Getenv("ADMIN_USUARIO"), os.Getenv("ADMIN_PASSWORD")
+	switch {
+	case usuario == "" && password == "":
+		return nil
+	case usuario == "":
+		return errors.New("ADMIN_PASSWORD está definido pero ADMIN_USUARIO no")
+	case password == "":
+		return errors.New("ADMIN_USUARIO está definido pero ADMIN_PASSWORD no")
+	case config.EsValorDeEjemplo(password):
+		return errors.New("ADMIN_PASSWORD conserva el valor de ejemplo; defina una contraseña propia")
+	case len(password) < auth.MinPasswordLen:
+		return fmt.Errorf("ADMIN_PASSWORD debe tener al menos %d caracteres", auth.MinPasswordLen)
+	}
+
+	ctx := context.Background()
+	_, err := usuarios.GetByUsuario(ctx, usuario)
+	if err == nil || !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	if err := usuarios.Create(ctx, &models.Usuario{Usuario: usuario, PasswordHash: hash, Rol: auth.RolAdmin}); err != nil {
+		return err
+	}
+	log.Printf("Usuario administrador %s creado", usuario)
+	return nil
+}
+
 func main() {
 	// Cargar variables de entorno
 	if err := godotenv.Load(); err != nil {
@@ -25,14 +70,104 @@ func main() {
 	}
 	defer db.Close()
 
+	// Subcomandos de línea de comandos, por ejemplo: server_estudiantes migrate up
+	// o server_estudiantes seed [archivo.json]
+	if len(os.Args) > 1 {
+		if err := ejecutarComando(db, os.Args[1:]); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+
+	// Cargar la política de calificación
+	politica, err := config.LoadPoliticaCalificacion()
+	if err != nil {
+		log.Fatalf("Error en la política de calificación: %v", err)
+	}
+
+	// Cargar los límites de la matrícula
+	reglas, err := config.LoadReglasMatricula()
+	if err != nil {
+		log.Fatalf("Error en las reglas de matrícula: %v", err)
+	}
+
+	// Cargar la configuración del envío de eventos al middleware
+	configOutbox, err := config.LoadConfigOutbox()
+	if err != nil {
+		log.Fatalf("Error en la configuración del outbox: %v", err)
+	}
+
+	// Cargar los tiempos de heartbeat y apagado
+	configServidor, err := config.LoadConfigServidor()
+	if err != nil {
+		log.Fatalf("Error en la configuración del servidor: %v", err)
+	}
+
+	// Aplicar las migraciones pendientes del esquema
+	if configServidor.MigrarAlIniciar {
+		if _, err := migraciones.Subir(context.Background(), db); err != nil {
+			log.Fatalf("Error al aplicar migraciones: %v", err)
+		}
+	}
+
+	// Cargar los límites de las conexiones WebSocket
+	configWebSocket, err := config.LoadConfigWebSocket()
+	if err != nil {
+		log.Fatalf("Error en la configuración de WebSocket: %v", err)
+	}
+
+	// Cargar el firmador de tokens de sesión
+	signer, err := auth.LoadSigner()
+	if err != nil {
+		log.Fatalf("Error en la configuración de autenticación: %v", err)
+	}
+
+	// Inicializar repositorios
+	store := repository.NewMySQLStore(db, politica, reglas)
+
+	// Crear el usuario administrador inicial si está configurado
+	if err := crearAdministrador(store.Usuarios); err != nil {
+		log.Fatalf("Error al crear el usuario administrador: %v", err)
+	}
+
+	// Hub de WebSocket para notificar cambios en tiempo real
+	hub := controllers.NewHub(configWebSocket)
+
 	// Inicializar controladores
-	estudiantesController := controllers.NewEstudiantesController(db)
-	asignaturasController := controllers.NewAsignaturasController(db)
-	profesoresController := controllers.NewProfesoresController(db)
-	ciclosController := controllers.NewCiclosController(db)
-	matriculasController := controllers.NewMatriculasController(db)
-	notasController := controllers.NewNotasController(db)
-	asignacionesController := controllers.NewAsignacionesController(db)
+	estudiantesController := controllers.NewEstudiantesController(store.Estudiantes, hub)
+	asignaturasController := controllers.NewAsignaturasController(store.Asignaturas)
+	profesoresController := controllers.NewProfesoresController(store.Profesores)
+	ciclosController := controllers.NewCiclosController(store.Ciclos)
+	matriculasController := controllers.NewMatriculasController(store.Matriculas, hub)
+	notasController := controllers.NewNotasController(store.Notas, store.Asignaciones, politica, hub)
+	prerrequisitosController := controllers.NewPrerrequisitosController(store.Prerrequisitos, store.Asignaturas)
+	kardexController := controllers.NewKardexController(store.Notas, store.Estudiantes, politica)
+	asignacionesController := controllers.NewAsignacionesController(store.Asignaciones, store.Prerrequisitos)
+	authController := controllers.NewAuthController(store.Usuarios, signer)
+	usuariosController := controllers.NewUsuariosController(store.Usuarios, store.Estudiantes, store.Profesores)
+	outboxController := controllers.NewOutboxController(store.Outbox)
+	syncController := controllers.NewSyncController(store.Sync)
+
+	// Tareas en segundo plano: se detienen después de drenar las solicitudes
+	ctxTareas, detenerTareas := context.WithCancel(context.Background())
+	var tareas sync.WaitGroup
+
+	// Enviar en segundo plano los cambios registrados en el outbox
+	dispatcher := middleware.NewDispatcher(store.Outbox, configOutbox)
+	tareas.Add(1)
+	go func() {
+		defer tareas.Done()
+		dispatcher.Run(ctxTareas)
+	}()
+
+	// Middleware de autenticación compartido por la API y el WebSocket
+	autenticar := middleware.AuthMiddleware(signer, store.Usuarios)
+
+	// Secreto del receptor /sync; sin él solo se envían cambios, no se reciben
+	syncToken := config.SyncToken()
+	if syncToken == "" {
+		log.Println("SYNC_TOKEN no definido o con el valor de ejemplo: el receptor /sync queda deshabilitado")
+	}
 
 	// Configurar rutas del backend
 	apiRouter := routes.SetupRoutes(
@@ -43,14 +178,24 @@ func main() {
 		matriculasController,
 		notasController,
 		asignacionesController,
+		prerrequisitosController,
+		kardexController,
+		authController,
+		usuariosController,
+		outboxController,
+		syncController,
+		hub,
+		autenticar,
+		syncToken,
 	)
 
-	// Aplicar middleware CORS a rutas del backend
-	apiHandler := middleware.CorsMiddleware(apiRouter)
+	// Aplicar middleware CORS a rutas del backend; el ID de solicitud cubre también
+	// las respuestas de rutas inexistentes
+	apiHandler := middleware.RequestIDMiddleware(middleware.CorsMiddleware(apiRouter))
 	http.Handle("/api/", http.StripPrefix("/api", apiHandler))
 
 	// Ruta para WebSocket
-	http.HandleFunc("/ws", controllers.WebSocketHandler)
+	http.Handle("/ws", autenticar(http.HandlerFunc(hub.HandleWebSocket)))
 
 	// Servir frontend (HTML + JS desde /frontend)
 	fs := http.FileServer(http.Dir("./frontend"))
@@ -63,6 +208,52 @@ func main() {
 	}
 
 	// Iniciar el servidor
-	log.Printf("Servidor iniciado en http://localhost:%s", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil)) // DefaultServeMux maneja todo
+	server := &http.Server{Addr: ":" + port} // DefaultServeMux maneja todo
+	errServidor := make(chan error, 1)
+	go func() {
+		errServidor <- server.ListenAndServe()
+	}()
+	log.Printf("Servidor iniciado en http://localhost:%s (versión %s)", port, config.Version)
+
+	// Registrar el servidor en el middleware y enviar heartbeats periódicos
+	if err := middleware.NotifyOnline(ctxTareas); err != nil {
+		log.Printf("Error al notificar al middleware: %v", err)
+	}
+	tareas.Add(1)
+	go func() {
+		defer tareas.Done()
+		middleware.Heartbeat(ctxTareas, db, configServidor.HeartbeatIntervalo)
+	}()
+
+	// Esperar SIGINT/SIGTERM o un error del servidor
+	interrupcion, detenerInterrupcion := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer detenerInterrupcion()
+
+	select {
+	case err := <-errServidor:
+		log.Printf("Error en el servidor: %v", err)
+	case <-interrupcion.Done():
+		log.Println("Deteniendo el servidor...")
+	}
+
+	// Dejar de aceptar conexiones y esperar a que terminen las solicitudes en curso
+	ctxApagado, cancelar := context.WithTimeout(context.Background(), configServidor.ShutdownTimeout)
+	defer cancelar()
+	if err := server.Shutdown(ctxApagado); err != nil {
+		log.Printf("Error al drenar las solicitudes: %v", err)
+	}
+
+	// Shutdown no espera a las conexiones WebSocket: se cierran explícitamente
+	hub.Cerrar()
+
+	detenerTareas()
+	tareas.Wait()
+
+	// El aviso tiene su propio plazo: el de drenado pudo agotarse esperando las tareas
+	ctxAviso, cancelarAviso := context.WithTimeout(context.Background(), middleware.EsperaAvisoOffline)
+	defer cancelarAviso()
+	if err := middleware.NotifyOffline(ctxAviso); err != nil {
+		log.Printf("Error al notificar al middleware: %v", err)
+	}
+	log.Println("Servidor detenido")
 }
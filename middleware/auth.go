@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net/http"
+	"server_estudiantes/auth"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// AuthMiddleware identifica al usuario a partir del token Bearer y lo agrega al contexto.
+// Las solicitudes sin token continúan como anónimas; un token inválido o vencido se rechaza con 401.
+func AuthMiddleware(signer *auth.Signer, usuarios repository.UsuarioRepo) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" && websocket.IsWebSocketUpgrade(r) && r.URL.Query().Get("token") != "" {
+				// Los navegadores no permiten cabeceras en el WebSocket: el token viaja en la URL
+				header = "Bearer " + r.URL.Query().Get("token")
+			}
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token, found := strings.CutPrefix(header, "Bearer ")
+			if !found {
+				WriteError(w, r, http.StatusUnauthorized, models.CodTokenInvalido, "Token de sesión inválido", nil)
+				return
+			}
+
+			hash, ok := signer.Verify(strings.TrimSpace(token))
+			if !ok {
+				WriteError(w, r, http.StatusUnauthorized, models.CodTokenInvalido, "Token de sesión inválido", nil)
+				return
+			}
+
+			u, err := usuarios.GetSesion(r.Context(), hash)
+			if errors.Is(err, repository.ErrNotFound) {
+				WriteError(w, r, http.StatusUnauthorized, models.CodSesionExpirada, "La sesión expiró o no existe", nil)
+				return
+			} else if err != nil {
+				log.Printf("Error al consultar sesión: %v", err)
+				WriteError(w, r, http.StatusInternalServerError, models.CodErrorInterno, "Error al verificar la sesión", nil)
+				return
+			}
+
+			ctx := auth.WithPrincipal(r.Context(), auth.Principal{
+				IDUsuario:    u.IDUsuario,
+				Usuario:      u.Usuario,
+				Rol:          u.Rol,
+				IDReferencia: u.IDReferencia,
+				Sesion:       hash,
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// RequireAuth rechaza con 401 las solicitudes sin usuario autenticado
+func RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.PrincipalFromContext(r.Context()); !ok {
+			WriteError(w, r, http.StatusUnauthorized, models.CodSesionRequerida, "Se requiere iniciar sesión", nil)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireSyncToken protege el receptor de sincronización con el secreto compartido
+// con el middleware, enviado en la cabecera X-Sync-Token; sin secreto configurado, o
+// con el valor de ejemplo, el receptor queda deshabilitado
+func RequireSyncToken(token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token == "" || config.EsValorDeEjemplo(token) {
+				WriteError(w, r, http.StatusServiceUnavailable, models.CodSyncDeshabilitado, "La sincronización entrante no está configurada", nil)
+				return
+			}
+			recibido := r.Header.Get("X-Sync-Token")
+			if subtle.ConstantTimeCompare([]byte(recibido), []byte(token)) != 1 {
+				WriteError(w, r, http.StatusUnauthorized, models.CodTokenSyncInvalido, "Token de sincronización inválido", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"server_estudiantes/auth"
+	"server_estudiantes/models"
+
+	"github.com/gorilla/mux"
+)
+
+// RequireRol permite la solicitud solo a los roles indicados; el administrador siempre tiene acceso
+func RequireRol(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := auth.PrincipalFromContext(r.Context())
+			if !ok {
+				WriteError(w, r, http.StatusUnauthorized, models.CodSesionRequerida, "Se requiere iniciar sesión", nil)
+				return
+			}
+			if p.Rol != auth.RolAdmin && !p.TieneRol(roles...) {
+				WriteError(w, r, http.StatusForbidden, models.CodSinPermiso, "No tiene permiso para realizar esta acción", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePropio permite a un estudiante acceder solo cuando el parámetro de ruta indicado
+// es su propio id_estudiantes; los roles indicados y el administrador acceden a cualquiera
+func RequirePropio(param string, roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, ok := auth.PrincipalFromContext(r.Context())
+			if !ok {
+				WriteError(w, r, http.StatusUnauthorized, models.CodSesionRequerida, "Se requiere iniciar sesión", nil)
+				return
+			}
+			propio := p.Rol == auth.RolEstudiante && p.IDReferencia == mux.Vars(r)[param]
+			if !propio && p.Rol != auth.RolAdmin && !p.TieneRol(roles...) {
+				WriteError(w, r, http.StatusForbidden, models.CodSinPermiso, "No tiene permiso para realizar esta acción", nil)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
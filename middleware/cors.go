@@ -14,8 +14,11 @@ func CorsMiddleware(next http.Handler) http.Handler {
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
 		
 		// Permitir encabezados específicos
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-Match, X-Request-ID")
 		
+		// Exponer la ETag para el control de concurrencia optimista y el ID de solicitud
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Request-ID")
+
 		// Manejar solicitudes preflight OPTIONS
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"server_estudiantes/config"
+	"server_estudiantes/repository"
+	"time"
+)
+
+// Dispatcher envía en segundo plano los eventos de la tabla outbox al middleware,
+// reintentando con retroceso exponencial hasta agotar los intentos configurados
+type Dispatcher struct {
+	Repo   repository.OutboxRepo
+	Config config.ConfigOutbox
+}
+
+// NewDispatcher crea un despachador de eventos del outbox
+func NewDispatcher(repo repository.OutboxRepo, cfg config.ConfigOutbox) *Dispatcher {
+	return &Dispatcher{Repo: repo, Config: cfg}
+}
+
+// Run procesa eventos pendientes hasta que se cancele el contexto
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.Config.Intervalo)
+	defer ticker.Stop()
+
+	for {
+		d.despachar(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// despachar envía un lote de eventos pendientes; la consulta devuelve como máximo un evento
+// por registro, así que un evento no se envía hasta que los anteriores de su fila salieron
+func (d *Dispatcher) despachar(ctx context.Context) {
+	eventos, err := d.Repo.Pendientes(ctx, d.Config.Lote)
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Error al consultar eventos pendientes del outbox: %v", err)
+		}
+		return
+	}
+
+	for _, e := range eventos {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := SendToMiddleware(ctx, e); err != nil {
+			// Un envío interrumpido por el apagado no cuenta como intento fallido
+			if ctx.Err() != nil {
+				return
+			}
+			intentos := e.Intentos + 1
+			if intentos >= d.Config.MaxIntentos {
+				log.Printf("Evento %s (%s %s) movido a fallidos tras %d intentos: %v", e.IDEvento, e.Operacion, e.Tabla, intentos, err)
+				err = d.Repo.MarcarFallido(ctx, e.ID, err.Error())
+			} else {
+				log.Printf("Error al enviar evento %s al middleware (intento %d): %v", e.IDEvento, intentos, err)
+				err = d.Repo.Reprogramar(ctx, e.ID, time.Now().Add(d.Config.Espera(intentos)), err.Error())
+			}
+			if err != nil {
+				log.Printf("Error al actualizar evento %s del outbox: %v", e.IDEvento, err)
+			}
+			continue
+		}
+
+		if err := d.Repo.MarcarEnviado(ctx, e.ID); err != nil {
+			log.Printf("Error al marcar evento %s como enviado: %v", e.IDEvento, err)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"server_estudiantes/models"
+)
+
+// WriteError responde con el sobre de error JSON de la API
+func WriteError(w http.ResponseWriter, r *http.Request, status int, code, message string, details interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(models.ErrorAPI{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: RequestIDFromContext(r.Context()),
+	})
+}
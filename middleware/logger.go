@@ -16,7 +16,8 @@ func LoggerMiddleware(next http.Handler) http.Handler {
 		
 		// Registrar información de la solicitud
 		log.Printf(
-			"%s %s %s",
+			"[%s] %s %s %s",
+			RequestIDFromContext(r.Context()),
 			r.Method,
 			r.RequestURI,
 			time.Since(start),
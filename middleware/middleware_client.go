@@ -2,54 +2,124 @@ package middleware
 
 import (
 	"bytes"
+	"context"
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
 	"time"
 )
 
-// NotifyOnline notifica al middleware que el servidor está en línea
-func NotifyOnline() error {
-	middlewareURL := os.Getenv("MIDDLEWARE_URL")
-	if middlewareURL == "" {
-		middlewareURL = "http://localhost:3001"
-	}
+// Origen identifica a este servidor en los eventos de sincronización
+const Origen = "estudiantes"
+
+// clienteMiddleware limita la espera de cada envío para no bloquear al despachador
+var clienteMiddleware = &http.Client{Timeout: 10 * time.Second}
 
-	data := map[string]interface{}{
-		"server":    "estudiantes",
-		"status":    "online",
-		"timestamp": time.Now().Format(time.RFC3339),
+// EsperaAvisoOffline limita el aviso de apagado, que se envía cuando el tiempo para
+// drenar las solicitudes ya pudo haberse agotado
+const EsperaAvisoOffline = 3 * time.Second
+
+// middlewareURL devuelve la URL del middleware de sincronización
+func middlewareURL() string {
+	if url := os.Getenv("MIDDLEWARE_URL"); url != "" {
+		return url
 	}
+	return "http://localhost:3001"
+}
+
+// notificarEstado envía al middleware el estado del servidor en la ruta indicada
+func notificarEstado(ctx context.Context, ruta string, data map[string]interface{}) error {
+	data["server"] = Origen
+	data["version"] = config.Version
+	data["timestamp"] = time.Now().Format(time.RFC3339)
 
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return err
 	}
 
-	resp, err := http.Post(middlewareURL+"/notify-online", "application/json", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, middlewareURL()+ruta, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := clienteMiddleware.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("el middleware respondió %s", resp.Status)
+	}
+	return nil
+}
+
+// NotifyOnline notifica al middleware que el servidor está en línea
+func NotifyOnline(ctx context.Context) error {
+	if err := notificarEstado(ctx, "/notify-online", map[string]interface{}{"status": "online"}); err != nil {
+		return err
+	}
+
 	log.Println("Servidor notificado como en línea al middleware")
 	return nil
 }
 
-// SendToMiddleware envía actualizaciones al middleware
-func SendToMiddleware(operation, table string, data interface{}) error {
-	middlewareURL := os.Getenv("MIDDLEWARE_URL")
-	if middlewareURL == "" {
-		middlewareURL = "http://localhost:3001"
+// NotifyOffline notifica al middleware que el servidor se está deteniendo
+func NotifyOffline(ctx context.Context) error {
+	if err := notificarEstado(ctx, "/notify-offline", map[string]interface{}{"status": "offline"}); err != nil {
+		return err
 	}
 
+	log.Println("Servidor notificado como fuera de línea al middleware")
+	return nil
+}
+
+// Heartbeat envía periódicamente el estado del servidor y de la base de datos
+// hasta que se cancele el contexto
+func Heartbeat(ctx context.Context, db *sql.DB, intervalo time.Duration) {
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		estadoDB := "ok"
+		pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		if err := db.PingContext(pingCtx); err != nil {
+			estadoDB = "error"
+			log.Printf("La base de datos no responde: %v", err)
+		}
+		cancel()
+
+		data := map[string]interface{}{"status": "online", "database": estadoDB}
+		if err := notificarEstado(ctx, "/heartbeat", data); err != nil && ctx.Err() == nil {
+			log.Printf("Error al enviar heartbeat al middleware: %v", err)
+		}
+	}
+}
+
+// SendToMiddleware envía un evento del outbox al middleware; el id del evento permite
+// que el receptor descarte duplicados cuando un envío se reintenta. Cancelar el contexto
+// interrumpe el envío en curso.
+func SendToMiddleware(ctx context.Context, e models.EventoOutbox) error {
 	payload := map[string]interface{}{
-		"operation": operation,
-		"table":     table,
-		"data":      data,
-		"source":    "estudiantes",
-		"timestamp": time.Now().Format(time.RFC3339),
+		"id":        e.IDEvento,
+		"operation": e.Operacion,
+		"table":     e.Tabla,
+		"data":      e.Payload,
+		"source":    Origen,
+		"timestamp": e.Creado.Format(time.RFC3339),
 	}
 
 	jsonData, err := json.Marshal(payload)
@@ -57,12 +127,25 @@ func SendToMiddleware(operation, table string, data interface{}) error {
 		return err
 	}
 
-	resp, err := http.Post(middlewareURL+"/sync", "application/json", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, middlewareURL()+"/sync", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := config.SyncToken(); token != "" {
+		req.Header.Set("X-Sync-Token", token)
+	}
+
+	resp, err := clienteMiddleware.Do(req)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	log.Printf("Operación %s en tabla %s enviada al middleware", operation, table)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("el middleware respondió %s", resp.Status)
+	}
+
+	log.Printf("Operación %s en tabla %s enviada al middleware", e.Operacion, e.Tabla)
 	return nil
 }
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"server_estudiantes/config"
+)
+
+type requestIDKey struct{}
+
+// RequestIDMiddleware asigna a cada solicitud un identificador, respetando el que
+// envíe el cliente en X-Request-ID, y lo devuelve en la misma cabecera
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" || len(id) > 64 {
+			var err error
+			if id, err = config.GenerateID(); err != nil {
+				id = ""
+			}
+		}
+
+		w.Header().Set("X-Request-ID", id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestIDFromContext devuelve el identificador de la solicitud, si existe
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
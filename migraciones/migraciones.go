@@ -0,0 +1,274 @@
+// Package migraciones aplica el esquema de la base de datos a partir de archivos SQL
+// versionados incluidos en el binario.
+//
+// Cada migración se compone de dos archivos en sql/: NNNN_nombre.up.sql y
+// NNNN_nombre.down.sql. Las versiones aplicadas se registran en la tabla schema_migrations.
+package migraciones
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed sql/*.sql
+var archivos embed.FS
+
+// nombreBloqueo serializa las migraciones cuando varias instancias arrancan a la vez
+const nombreBloqueo = "server_estudiantes_migraciones"
+
+// Migracion es una versión del esquema con sus sentencias de subida y bajada
+type Migracion struct {
+	Version int
+	Nombre  string
+	Up      string
+	Down    string
+}
+
+// Estado indica si una migración está aplicada y desde cuándo
+type Estado struct {
+	Version  int
+	Nombre   string
+	Aplicada *time.Time
+}
+
+// Cargar lee las migraciones incluidas en el binario ordenadas por versión
+func Cargar() ([]Migracion, error) {
+	entradas, err := fs.ReadDir(archivos, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	porVersion := map[int]*Migracion{}
+	for _, entrada := range entradas {
+		base, sentido, ok := strings.Cut(strings.TrimSuffix(entrada.Name(), ".sql"), ".")
+		if !ok || (sentido != "up" && sentido != "down") {
+			return nil, fmt.Errorf("nombre de migración inválido: %s", entrada.Name())
+		}
+		numero, nombre, _ := strings.Cut(base, "_")
+		version, err := strconv.Atoi(numero)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("versión de migración inválida: %s", entrada.Name())
+		}
+
+		contenido, err := archivos.ReadFile("sql/" + entrada.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, existe := porVersion[version]
+		if !existe {
+			m = &Migracion{Version: version, Nombre: nombre}
+			porVersion[version] = m
+		} else if m.Nombre != nombre {
+			return nil, fmt.Errorf("la versión %d tiene dos nombres: %s y %s", version, m.Nombre, nombre)
+		}
+		if sentido == "up" {
+			m.Up = string(contenido)
+		} else {
+			m.Down = string(contenido)
+		}
+	}
+
+	migraciones := make([]Migracion, 0, len(porVersion))
+	for _, m := range porVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("la migración %04d_%s debe tener archivos up y down", m.Version, m.Nombre)
+		}
+		migraciones = append(migraciones, *m)
+	}
+	sort.Slice(migraciones, func(i, j int) bool { return migraciones[i].Version < migraciones[j].Version })
+	return migraciones, nil
+}
+
+// Subir aplica en orden todas las migraciones pendientes y devuelve cuántas aplicó
+func Subir(ctx context.Context, db *sql.DB) (int, error) {
+	migraciones, err := Cargar()
+	if err != nil {
+		return 0, err
+	}
+
+	aplicadas := 0
+	err = conBloqueo(ctx, db, func(conn *sql.Conn) error {
+		versiones, err := versionesAplicadas(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migraciones {
+			if _, ok := versiones[m.Version]; ok {
+				continue
+			}
+			if err := ejecutar(ctx, conn, m.Up); err != nil {
+				return fmt.Errorf("migración %04d_%s: %w", m.Version, m.Nombre, err)
+			}
+			_, err := conn.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, nombre, aplicada) VALUES (?, ?, ?)",
+				m.Version, m.Nombre, time.Now(),
+			)
+			if err != nil {
+				return err
+			}
+			log.Printf("Migración %04d_%s aplicada", m.Version, m.Nombre)
+			aplicadas++
+		}
+		return nil
+	})
+	return aplicadas, err
+}
+
+// Bajar revierte las últimas migraciones aplicadas, como máximo pasos, y devuelve cuántas revirtió
+func Bajar(ctx context.Context, db *sql.DB, pasos int) (int, error) {
+	migraciones, err := Cargar()
+	if err != nil {
+		return 0, err
+	}
+
+	revertidas := 0
+	err = conBloqueo(ctx, db, func(conn *sql.Conn) error {
+		versiones, err := versionesAplicadas(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migraciones) - 1; i >= 0 && revertidas < pasos; i-- {
+			m := migraciones[i]
+			if _, ok := versiones[m.Version]; !ok {
+				continue
+			}
+			if err := ejecutar(ctx, conn, m.Down); err != nil {
+				return fmt.Errorf("reversión %04d_%s: %w", m.Version, m.Nombre, err)
+			}
+			if _, err := conn.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", m.Version); err != nil {
+				return err
+			}
+			log.Printf("Migración %04d_%s revertida", m.Version, m.Nombre)
+			revertidas++
+		}
+		return nil
+	})
+	return revertidas, err
+}
+
+// Estados devuelve todas las migraciones conocidas indicando cuáles están aplicadas
+func Estados(ctx context.Context, db *sql.DB) ([]Estado, error) {
+	migraciones, err := Cargar()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	versiones, err := versionesAplicadas(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	estados := make([]Estado, 0, len(migraciones))
+	for _, m := range migraciones {
+		e := Estado{Version: m.Version, Nombre: m.Nombre}
+		if aplicada, ok := versiones[m.Version]; ok {
+			e.Aplicada = &aplicada
+		}
+		estados = append(estados, e)
+	}
+	return estados, nil
+}
+
+// conBloqueo ejecuta fn en una conexión dedicada que mantiene el bloqueo de migraciones
+func conBloqueo(ctx context.Context, db *sql.DB, fn func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var obtenido sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 60)", nombreBloqueo).Scan(&obtenido); err != nil {
+		return err
+	}
+	if obtenido.Int64 != 1 {
+		return fmt.Errorf("no se pudo obtener el bloqueo de migraciones")
+	}
+	defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", nombreBloqueo)
+
+	return fn(conn)
+}
+
+// versionesAplicadas crea la tabla schema_migrations si no existe y devuelve las versiones registradas
+func versionesAplicadas(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	_, err := conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version  INT          NOT NULL,
+			nombre   VARCHAR(255) NOT NULL,
+			aplicada DATETIME     NOT NULL,
+			PRIMARY KEY (version)
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, "SELECT version, aplicada FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versiones := map[int]time.Time{}
+	for rows.Next() {
+		var version int
+		var aplicada time.Time
+		if err := rows.Scan(&version, &aplicada); err != nil {
+			return nil, err
+		}
+		versiones[version] = aplicada
+	}
+	return versiones, rows.Err()
+}
+
+// ejecutar corre una a una las sentencias de un archivo de migración. En MySQL las
+// sentencias DDL confirman la transacción implícitamente, por lo que una migración
+// que falla a medias debe corregirse a mano antes de reintentar.
+func ejecutar(ctx context.Context, conn *sql.Conn, contenido string) error {
+	for _, sentencia := range sentencias(contenido) {
+		if _, err := conn.ExecContext(ctx, sentencia); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sentencias separa el contenido de un archivo en sentencias terminadas en ';' al
+// final de línea, descartando los comentarios de línea completa
+func sentencias(contenido string) []string {
+	var resultado []string
+	var actual strings.Builder
+	for _, linea := range strings.Split(contenido, "\n") {
+		recortada := strings.TrimSpace(linea)
+		if recortada == "" || strings.HasPrefix(recortada, "--") {
+			continue
+		}
+		actual.WriteString(linea)
+		actual.WriteString("\n")
+		if strings.HasSuffix(recortada, ";") {
+			resultado = append(resultado, strings.TrimSuffix(strings.TrimSpace(actual.String()), ";"))
+			actual.Reset()
+		}
+	}
+	if resto := strings.TrimSpace(actual.String()); resto != "" {
+		resultado = append(resultado, resto)
+	}
+	return resultado
+}
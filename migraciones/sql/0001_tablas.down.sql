@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS sync_eventos;
+DROP TABLE IF EXISTS outbox;
+DROP TABLE IF EXISTS sesiones;
+DROP TABLE IF EXISTS usuarios;
+DROP TABLE IF EXISTS registro_notas;
+DROP TABLE IF EXISTS matriculas;
+DROP TABLE IF EXISTS profesores_ciclos_asignaturas;
+DROP TABLE IF EXISTS ciclos;
+DROP TABLE IF EXISTS asignaturas;
+DROP TABLE IF EXISTS profesores;
+DROP TABLE IF EXISTS estudiantes;
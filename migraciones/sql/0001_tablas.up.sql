@@ -0,0 +1,107 @@
+-- Tablas del servidor. IF NOT EXISTS permite adoptar bases creadas antes de las migraciones.
+
+CREATE TABLE IF NOT EXISTS estudiantes (
+    id_            VARCHAR(20)  NOT NULL,
+    id_estudiantes VARCHAR(20)  NOT NULL,
+    nombre         VARCHAR(100) NOT NULL,
+    version        INT          NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS profesores (
+    id_           VARCHAR(20)  NOT NULL,
+    id_profesores VARCHAR(20)  NOT NULL,
+    nombre        VARCHAR(100) NOT NULL,
+    version       INT          NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS asignaturas (
+    id_               VARCHAR(20)  NOT NULL,
+    id_asignaturas    VARCHAR(20)  NOT NULL,
+    nombre_asignatura VARCHAR(100) NOT NULL,
+    version           INT          NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS ciclos (
+    id_       VARCHAR(20) NOT NULL,
+    id_ciclos VARCHAR(20) NOT NULL,
+    ciclo     VARCHAR(50) NOT NULL,
+    version   INT         NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS profesores_ciclos_asignaturas (
+    id_                              VARCHAR(20) NOT NULL,
+    id_profesores_ciclos_asignaturas VARCHAR(20) NOT NULL,
+    id_profesores                    VARCHAR(20) NOT NULL,
+    id_asignaturas                   VARCHAR(20) NOT NULL,
+    id_ciclos                        VARCHAR(20) NOT NULL,
+    version                          INT         NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS matriculas (
+    id_                              VARCHAR(20) NOT NULL,
+    id_matriculas                    VARCHAR(20) NOT NULL,
+    id_estudiantes                   VARCHAR(20) NOT NULL,
+    id_profesores_ciclos_asignaturas VARCHAR(20) NOT NULL,
+    version                          INT         NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS registro_notas (
+    id_               VARCHAR(20)  NOT NULL,
+    id_registro_notas VARCHAR(20)  NOT NULL,
+    id_matriculas     VARCHAR(20)  NOT NULL,
+    nota1             DECIMAL(5,2) NOT NULL DEFAULT 0,
+    nota2             DECIMAL(5,2) NOT NULL DEFAULT 0,
+    sup               INT          NOT NULL DEFAULT 0,
+    version           INT          NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS usuarios (
+    id_           VARCHAR(20)  NOT NULL,
+    id_usuarios   VARCHAR(20)  NOT NULL,
+    usuario       VARCHAR(50)  NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    rol           VARCHAR(20)  NOT NULL,
+    id_referencia VARCHAR(20)  NULL,
+    version       INT          NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sesiones (
+    token_hash  CHAR(64)    NOT NULL,
+    id_usuarios VARCHAR(20) NOT NULL,
+    expira      DATETIME    NOT NULL,
+    PRIMARY KEY (token_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id              BIGINT       NOT NULL AUTO_INCREMENT,
+    id_evento       VARCHAR(20)  NOT NULL,
+    operacion       VARCHAR(10)  NOT NULL,
+    tabla           VARCHAR(64)  NOT NULL,
+    id_registro     VARCHAR(20)  NOT NULL,
+    payload         JSON         NOT NULL,
+    estado          VARCHAR(20)  NOT NULL,
+    intentos        INT          NOT NULL DEFAULT 0,
+    ultimo_error    TEXT         NULL,
+    creado          DATETIME     NOT NULL,
+    proximo_intento DATETIME     NOT NULL,
+    enviado         DATETIME     NULL,
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS sync_eventos (
+    id_evento   VARCHAR(64) NOT NULL,
+    origen      VARCHAR(64) NOT NULL,
+    operacion   VARCHAR(10) NOT NULL,
+    tabla       VARCHAR(64) NOT NULL,
+    id_registro VARCHAR(64) NOT NULL,
+    recibido    DATETIME    NOT NULL,
+    PRIMARY KEY (id_evento)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,45 @@
+ALTER TABLE outbox
+    DROP INDEX ix_outbox_registro,
+    DROP INDEX ix_outbox_estado,
+    DROP INDEX uq_outbox_evento;
+
+-- Al quitar una clave foránea MySQL conserva el índice que creó para ella
+ALTER TABLE sesiones
+    DROP FOREIGN KEY fk_sesiones_usuario;
+ALTER TABLE sesiones
+    DROP INDEX fk_sesiones_usuario,
+    DROP INDEX ix_sesiones_expira;
+
+ALTER TABLE usuarios
+    DROP INDEX ix_usuarios_referencia,
+    DROP INDEX uq_usuarios_usuario,
+    DROP INDEX uq_usuarios_id;
+
+ALTER TABLE registro_notas
+    DROP FOREIGN KEY fk_registro_notas_matricula;
+ALTER TABLE registro_notas
+    DROP INDEX uq_registro_notas_matricula,
+    DROP INDEX uq_registro_notas_id;
+
+ALTER TABLE matriculas
+    DROP FOREIGN KEY fk_matriculas_estudiante,
+    DROP FOREIGN KEY fk_matriculas_asignacion;
+ALTER TABLE matriculas
+    DROP INDEX fk_matriculas_asignacion,
+    DROP INDEX uq_matriculas_estudiante_asignacion,
+    DROP INDEX uq_matriculas_id;
+
+ALTER TABLE profesores_ciclos_asignaturas
+    DROP FOREIGN KEY fk_pca_profesor,
+    DROP FOREIGN KEY fk_pca_asignatura,
+    DROP FOREIGN KEY fk_pca_ciclo;
+ALTER TABLE profesores_ciclos_asignaturas
+    DROP INDEX fk_pca_asignatura,
+    DROP INDEX fk_pca_ciclo,
+    DROP INDEX uq_pca_profesor_asignatura_ciclo,
+    DROP INDEX uq_pca_id;
+
+ALTER TABLE ciclos DROP INDEX uq_ciclos_id;
+ALTER TABLE asignaturas DROP INDEX uq_asignaturas_id;
+ALTER TABLE profesores DROP INDEX uq_profesores_id;
+ALTER TABLE estudiantes DROP INDEX uq_estudiantes_id;
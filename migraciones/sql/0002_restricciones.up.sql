@@ -0,0 +1,46 @@
+-- Claves únicas y foráneas que antes se verificaban con consultas COUNT desde el código.
+-- Los borrados usan RESTRICT: el repositorio traduce el error a ErrInUse.
+
+ALTER TABLE estudiantes
+    ADD CONSTRAINT uq_estudiantes_id UNIQUE (id_estudiantes);
+
+ALTER TABLE profesores
+    ADD CONSTRAINT uq_profesores_id UNIQUE (id_profesores);
+
+ALTER TABLE asignaturas
+    ADD CONSTRAINT uq_asignaturas_id UNIQUE (id_asignaturas);
+
+ALTER TABLE ciclos
+    ADD CONSTRAINT uq_ciclos_id UNIQUE (id_ciclos);
+
+ALTER TABLE profesores_ciclos_asignaturas
+    ADD CONSTRAINT uq_pca_id UNIQUE (id_profesores_ciclos_asignaturas),
+    ADD CONSTRAINT uq_pca_profesor_asignatura_ciclo UNIQUE (id_profesores, id_asignaturas, id_ciclos),
+    ADD CONSTRAINT fk_pca_profesor FOREIGN KEY (id_profesores) REFERENCES profesores (id_profesores) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_pca_asignatura FOREIGN KEY (id_asignaturas) REFERENCES asignaturas (id_asignaturas) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_pca_ciclo FOREIGN KEY (id_ciclos) REFERENCES ciclos (id_ciclos) ON DELETE RESTRICT;
+
+ALTER TABLE matriculas
+    ADD CONSTRAINT uq_matriculas_id UNIQUE (id_matriculas),
+    ADD CONSTRAINT uq_matriculas_estudiante_asignacion UNIQUE (id_estudiantes, id_profesores_ciclos_asignaturas),
+    ADD CONSTRAINT fk_matriculas_estudiante FOREIGN KEY (id_estudiantes) REFERENCES estudiantes (id_estudiantes) ON DELETE RESTRICT,
+    ADD CONSTRAINT fk_matriculas_asignacion FOREIGN KEY (id_profesores_ciclos_asignaturas) REFERENCES profesores_ciclos_asignaturas (id_profesores_ciclos_asignaturas) ON DELETE RESTRICT;
+
+ALTER TABLE registro_notas
+    ADD CONSTRAINT uq_registro_notas_id UNIQUE (id_registro_notas),
+    ADD CONSTRAINT uq_registro_notas_matricula UNIQUE (id_matriculas),
+    ADD CONSTRAINT fk_registro_notas_matricula FOREIGN KEY (id_matriculas) REFERENCES matriculas (id_matriculas) ON DELETE RESTRICT;
+
+ALTER TABLE usuarios
+    ADD CONSTRAINT uq_usuarios_id UNIQUE (id_usuarios),
+    ADD CONSTRAINT uq_usuarios_usuario UNIQUE (usuario),
+    ADD INDEX ix_usuarios_referencia (rol, id_referencia);
+
+ALTER TABLE sesiones
+    ADD CONSTRAINT fk_sesiones_usuario FOREIGN KEY (id_usuarios) REFERENCES usuarios (id_usuarios) ON DELETE CASCADE,
+    ADD INDEX ix_sesiones_expira (expira);
+
+ALTER TABLE outbox
+    ADD CONSTRAINT uq_outbox_evento UNIQUE (id_evento),
+    ADD INDEX ix_outbox_estado (estado, proximo_intento),
+    ADD INDEX ix_outbox_registro (tabla, id_registro, estado);
@@ -0,0 +1,5 @@
+-- Vista de los eventos que agotaron sus reintentos (dead letter), para consultas operativas
+CREATE OR REPLACE VIEW outbox_fallidos AS
+SELECT id, id_evento, operacion, tabla, id_registro, intentos, ultimo_error, creado, proximo_intento
+FROM outbox
+WHERE estado = 'fallido';
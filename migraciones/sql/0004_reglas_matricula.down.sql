@@ -0,0 +1,6 @@
+ALTER TABLE ciclos
+    DROP COLUMN fin_matricula,
+    DROP COLUMN inicio_matricula;
+
+ALTER TABLE profesores_ciclos_asignaturas
+    DROP COLUMN cupo;
@@ -0,0 +1,9 @@
+-- Reglas de matrícula: cupo por asignación y período de matrícula por ciclo.
+-- Un cupo o una fecha en NULL significa sin límite.
+
+ALTER TABLE profesores_ciclos_asignaturas
+    ADD COLUMN cupo INT NULL AFTER id_ciclos;
+
+ALTER TABLE ciclos
+    ADD COLUMN inicio_matricula DATETIME NULL AFTER ciclo,
+    ADD COLUMN fin_matricula    DATETIME NULL AFTER inicio_matricula;
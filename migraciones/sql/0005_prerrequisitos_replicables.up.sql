@@ -0,0 +1,16 @@
+-- Prerrequisitos entre asignaturas, con claves y versión propias para replicarse como el
+-- resto de las tablas. Ambas claves foráneas son RESTRICT: borrar una asignatura en cascada
+-- eliminaría prerrequisitos sin registrar su eliminación en el outbox.
+
+CREATE TABLE prerrequisitos (
+    id_                     VARCHAR(20) NOT NULL,
+    id_prerrequisitos       VARCHAR(20) NOT NULL,
+    id_asignaturas          VARCHAR(20) NOT NULL,
+    id_asignatura_requerida VARCHAR(20) NOT NULL,
+    version                 INT         NOT NULL DEFAULT 1,
+    PRIMARY KEY (id_),
+    CONSTRAINT uq_prerrequisitos_id UNIQUE (id_prerrequisitos),
+    CONSTRAINT uq_prerrequisitos_par UNIQUE (id_asignaturas, id_asignatura_requerida),
+    CONSTRAINT fk_prerrequisitos_asignatura FOREIGN KEY (id_asignaturas) REFERENCES asignaturas (id_asignaturas) ON DELETE RESTRICT,
+    CONSTRAINT fk_prerrequisitos_requerida FOREIGN KEY (id_asignatura_requerida) REFERENCES asignaturas (id_asignaturas) ON DELETE RESTRICT
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,3 @@
+ALTER TABLE asignaturas
+    DROP COLUMN horas_semanales,
+    DROP COLUMN creditos;
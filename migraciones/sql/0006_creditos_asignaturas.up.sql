@@ -0,0 +1,6 @@
+-- Créditos y horas semanales de cada asignatura. Las asignaturas existentes quedan con
+-- un crédito, el valor que el kardex asumía hasta ahora.
+
+ALTER TABLE asignaturas
+    ADD COLUMN creditos        INT NOT NULL DEFAULT 1 AFTER nombre_asignatura,
+    ADD COLUMN horas_semanales INT NOT NULL DEFAULT 0 AFTER creditos;
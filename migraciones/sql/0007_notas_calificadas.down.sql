@@ -0,0 +1,2 @@
+ALTER TABLE registro_notas
+    DROP COLUMN calificado;
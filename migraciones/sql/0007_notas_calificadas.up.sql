@@ -0,0 +1,8 @@
+-- Marca explícita de que un registro de notas ya fue calificado, para distinguir un
+-- registro pendiente de uno calificado con cero. Los registros existentes se consideran
+-- calificados si tienen alguna nota distinta de cero, la regla que se usaba hasta ahora.
+
+ALTER TABLE registro_notas
+    ADD COLUMN calificado BOOLEAN NOT NULL DEFAULT FALSE AFTER sup;
+
+UPDATE registro_notas SET calificado = TRUE WHERE nota1 <> 0 OR nota2 <> 0 OR sup <> 0;
@@ -2,14 +2,18 @@ package models
 
 // Asignacion representa una asignación de profesor a asignatura y ciclo
 type Asignacion struct {
-	ID          string `json:"id_"`
+	ID           string `json:"id_"`
 	IDAsignacion string `json:"id_profesores_ciclos_asignaturas"`
 	IDProfesor   string `json:"id_profesores"`
 	IDAsignatura string `json:"id_asignaturas"`
 	IDCiclo      string `json:"id_ciclos"`
-	Version      int    `json:"version"`
+	// Cupo es el máximo de estudiantes matriculados; nil significa sin límite
+	Cupo    *int `json:"cupo"`
+	Version int  `json:"version"`
 	// Campos adicionales para consultas
 	NombreProfesor   string `json:"nombre_profesor,omitempty"`
 	NombreAsignatura string `json:"nombre_asignatura,omitempty"`
+	Creditos         int    `json:"creditos"`
+	HorasSemanales   int    `json:"horas_semanales"`
 	Ciclo            string `json:"ciclo,omitempty"`
 }
@@ -2,8 +2,10 @@ package models
 
 // Asignatura representa una asignatura en el sistema
 type Asignatura struct {
-	ID            string `json:"id_"`
-	IDAsignatura  string `json:"id_asignaturas"`
-	Nombre        string `json:"nombre_asignatura"`
-	Version       int    `json:"version"`
+	ID             string `json:"id_"`
+	IDAsignatura   string `json:"id_asignaturas"`
+	Nombre         string `json:"nombre_asignatura"`
+	Creditos       int    `json:"creditos"`
+	HorasSemanales int    `json:"horas_semanales"`
+	Version        int    `json:"version"`
 }
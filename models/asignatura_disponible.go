@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// AsignaturaDisponible es una asignación en la que un estudiante puede matricularse
+type AsignaturaDisponible struct {
+	IDAsignacion     string `json:"id_profesores_ciclos_asignaturas"`
+	IDAsignatura     string `json:"id_asignaturas"`
+	NombreAsignatura string `json:"nombre_asignatura"`
+	Creditos         int    `json:"creditos"`
+	HorasSemanales   int    `json:"horas_semanales"`
+	IDProfesor       string `json:"id_profesores"`
+	NombreProfesor   string `json:"nombre_profesor"`
+	IDCiclo          string `json:"id_ciclos"`
+	Ciclo            string `json:"ciclo"`
+	// Cupo es el máximo de la asignación y CuposDisponibles los que quedan; ambos
+	// son nil si la asignación no tiene límite
+	Cupo             *int `json:"cupo"`
+	CuposDisponibles *int `json:"cupos_disponibles"`
+	// Fin del período de matrícula del ciclo, si tiene
+	FinMatricula *time.Time `json:"fin_matricula"`
+}
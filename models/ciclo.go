@@ -1,9 +1,14 @@
 package models
 
+import "time"
+
 // Ciclo representa un ciclo académico en el sistema
 type Ciclo struct {
 	ID      string `json:"id_"`
 	IDCiclo string `json:"id_ciclos"`
 	Ciclo   string `json:"ciclo"`
-	Version int    `json:"version"`
+	// Período de matrícula; sin fechas la matrícula está abierta
+	InicioMatricula *time.Time `json:"inicio_matricula"`
+	FinMatricula    *time.Time `json:"fin_matricula"`
+	Version         int        `json:"version"`
 }
@@ -0,0 +1,66 @@
+package models
+
+// ErrorAPI es el cuerpo JSON de todas las respuestas de error de la API
+type ErrorAPI struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+}
+
+// ErrorCampo describe un campo del cuerpo que no cumple sus reglas de validación
+type ErrorCampo struct {
+	Campo   string `json:"field"`
+	Regla   string `json:"rule"`
+	Mensaje string `json:"message"`
+}
+
+// Códigos de error estables que el cliente puede usar para decidir cómo reaccionar
+const (
+	CodCuerpoInvalido        = "INVALID_BODY"
+	CodCuerpoDemasiadoGrande = "PAYLOAD_TOO_LARGE"
+	CodValidacion            = "VALIDATION_FAILED"
+	CodParametroInvalido     = "INVALID_PARAMETER"
+	CodMetodoNoPermitido     = "METHOD_NOT_ALLOWED"
+	CodSesionRequerida       = "AUTHENTICATION_REQUIRED"
+	CodTokenInvalido         = "INVALID_TOKEN"
+	CodSesionExpirada        = "SESSION_EXPIRED"
+	CodCredencialesInvalidas = "INVALID_CREDENTIALS"
+	CodSinPermiso            = "FORBIDDEN"
+
+	CodNoEncontrado              = "NOT_FOUND"
+	CodEstudianteNoEncontrado    = "STUDENT_NOT_FOUND"
+	CodProfesorNoEncontrado      = "TEACHER_NOT_FOUND"
+	CodAsignaturaNoEncontrada    = "SUBJECT_NOT_FOUND"
+	CodCicloNoEncontrado         = "CYCLE_NOT_FOUND"
+	CodAsignacionNoEncontrada    = "ASSIGNMENT_NOT_FOUND"
+	CodMatriculaNoEncontrada     = "ENROLLMENT_NOT_FOUND"
+	CodNotaNoEncontrada          = "GRADE_RECORD_NOT_FOUND"
+	CodUsuarioNoEncontrado       = "USER_NOT_FOUND"
+	CodEventoNoEncontrado        = "EVENT_NOT_FOUND"
+	CodPrerrequisitoNoEncontrado = "PREREQUISITE_NOT_FOUND"
+
+	CodYaMatriculado           = "ALREADY_ENROLLED"
+	CodAsignacionDuplicada     = "DUPLICATE_ASSIGNMENT"
+	CodUsuarioDuplicado        = "USERNAME_TAKEN"
+	CodEstudianteConMatriculas = "STUDENT_HAS_ENROLLMENTS"
+	CodAsignaturaEnUso         = "SUBJECT_IN_USE"
+	CodProfesorEnUso           = "TEACHER_IN_USE"
+	CodCicloEnUso              = "CYCLE_IN_USE"
+	CodAsignacionConMatriculas = "ASSIGNMENT_HAS_ENROLLMENTS"
+	CodVersionNoCoincide       = "VERSION_MISMATCH"
+
+	CodMatriculaCerrada         = "ENROLLMENT_CLOSED"
+	CodAsignaturaYaMatriculada  = "SUBJECT_ALREADY_ENROLLED_IN_CYCLE"
+	CodCupoAgotado              = "SECTION_FULL"
+	CodPrerrequisitosPendientes = "PREREQUISITES_NOT_MET"
+	CodLimiteCreditos           = "CREDIT_LIMIT_EXCEEDED"
+	CodPrerrequisitoDuplicado   = "DUPLICATE_PREREQUISITE"
+	CodCicloPrerrequisitos      = "PREREQUISITE_CYCLE"
+
+	CodEventoSyncInvalido = "INVALID_SYNC_EVENT"
+	CodTokenSyncInvalido  = "INVALID_SYNC_TOKEN"
+	CodSyncDeshabilitado  = "SYNC_DISABLED"
+
+	CodErrorInterno = "INTERNAL_ERROR"
+)
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// Estados de una asignatura en el kardex
+const (
+	KardexAprobada  = "aprobada"
+	KardexReprobada = "reprobada"
+	KardexEnCurso   = "en_curso"
+)
+
+// Kardex es el historial académico de un estudiante agrupado por ciclo
+type Kardex struct {
+	IDEstudiante     string        `json:"id_estudiantes"`
+	NombreEstudiante string        `json:"nombre_estudiante"`
+	Ciclos           []KardexCiclo `json:"ciclos"`
+	// Promedio ponderado por créditos de todas las asignaturas finalizadas; nil si no hay ninguna
+	PromedioGeneral   *float64  `json:"promedio_general"`
+	CreditosInscritos int       `json:"creditos_inscritos"`
+	CreditosAprobados int       `json:"creditos_aprobados"`
+	Generado          time.Time `json:"generado"`
+}
+
+// KardexCiclo resume las asignaturas cursadas en un ciclo y el acumulado hasta él
+type KardexCiclo struct {
+	IDCiclo     string             `json:"id_ciclos"`
+	Ciclo       string             `json:"ciclo"`
+	Asignaturas []KardexAsignatura `json:"asignaturas"`
+	// Los promedios solo consideran asignaturas finalizadas y son nil si no hay ninguna
+	Promedio           *float64 `json:"promedio"`
+	CreditosInscritos  int      `json:"creditos_inscritos"`
+	CreditosAprobados  int      `json:"creditos_aprobados"`
+	PromedioAcumulado  *float64 `json:"promedio_acumulado"`
+	CreditosAcumulados int      `json:"creditos_acumulados"`
+}
+
+// KardexAsignatura es el resultado de una matrícula en el kardex
+type KardexAsignatura struct {
+	IDMatricula      string  `json:"id_matriculas"`
+	IDAsignatura     string  `json:"id_asignaturas"`
+	NombreAsignatura string  `json:"nombre_asignatura"`
+	NombreProfesor   string  `json:"nombre_profesor"`
+	Creditos         int     `json:"creditos"`
+	Nota1            float64 `json:"nota1"`
+	Nota2            float64 `json:"nota2"`
+	Sup              int     `json:"sup"`
+	NotaFinal        float64 `json:"nota_final"`
+	Estado           string  `json:"estado"`
+}
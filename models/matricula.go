@@ -11,5 +11,7 @@ type Matricula struct {
 	NombreEstudiante string `json:"nombre_estudiante,omitempty"`
 	NombreProfesor   string `json:"nombre_profesor,omitempty"`
 	NombreAsignatura string `json:"nombre_asignatura,omitempty"`
+	Creditos         int    `json:"creditos"`
+	HorasSemanales   int    `json:"horas_semanales"`
 	Ciclo            string `json:"ciclo,omitempty"`
 }
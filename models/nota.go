@@ -2,16 +2,28 @@ package models
 
 // Nota representa un registro de notas de un estudiante
 type Nota struct {
-	ID        string  `json:"id_"`
-	IDNota    string  `json:"id_registro_notas"`
-	IDMatricula string `json:"id_matriculas"`
-	Nota1     float64 `json:"nota1"`
-	Nota2     float64 `json:"nota2"`
-	Sup       int     `json:"sup"`
-	Version   int     `json:"version"`
+	ID          string  `json:"id_"`
+	IDNota      string  `json:"id_registro_notas"`
+	IDMatricula string  `json:"id_matriculas"`
+	Nota1       float64 `json:"nota1"`
+	Nota2       float64 `json:"nota2"`
+	Sup         int     `json:"sup"`
+	Version     int     `json:"version"`
+	// Calificado indica que el registro ya tiene calificaciones, aunque sean cero
+	Calificado bool `json:"calificado"`
+	// Campos calculados según la política de calificación
+	Promedio  float64 `json:"promedio"`
+	NotaFinal float64 `json:"nota_final"`
+	Estado    string  `json:"estado"`
 	// Campos adicionales para consultas
+	IDEstudiante     string `json:"id_estudiantes,omitempty"`
+	IDAsignacion     string `json:"id_profesores_ciclos_asignaturas,omitempty"`
+	IDProfesor       string `json:"id_profesores,omitempty"`
+	IDAsignatura     string `json:"id_asignaturas,omitempty"`
+	IDCiclo          string `json:"id_ciclos,omitempty"`
 	NombreEstudiante string `json:"nombre_estudiante,omitempty"`
 	NombreProfesor   string `json:"nombre_profesor,omitempty"`
 	NombreAsignatura string `json:"nombre_asignatura,omitempty"`
+	Creditos         int    `json:"creditos"`
 	Ciclo            string `json:"ciclo,omitempty"`
 }
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Operaciones de sincronización enviadas al middleware
+const (
+	OperacionCrear      = "CREATE"
+	OperacionActualizar = "UPDATE"
+	OperacionEliminar   = "DELETE"
+)
+
+// Estados de un evento en la tabla outbox
+const (
+	EventoPendiente = "pendiente"
+	EventoEnviado   = "enviado"
+	EventoFallido   = "fallido"
+)
+
+// EventoOutbox representa un cambio pendiente de enviar al middleware de sincronización
+type EventoOutbox struct {
+	ID             int64           `json:"id"`
+	IDEvento       string          `json:"id_evento"`
+	Operacion      string          `json:"operacion"`
+	Tabla          string          `json:"tabla"`
+	IDRegistro     string          `json:"id_registro"`
+	Payload        json.RawMessage `json:"payload"`
+	Estado         string          `json:"estado"`
+	Intentos       int             `json:"intentos"`
+	UltimoError    string          `json:"ultimo_error,omitempty"`
+	Creado         time.Time       `json:"creado"`
+	ProximoIntento time.Time       `json:"proximo_intento"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// Pagina es el sobre de respuesta de los listados paginados
+type Pagina[T any] struct {
+	Data       []T    `json:"data"`
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
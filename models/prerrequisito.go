@@ -0,0 +1,19 @@
+package models
+
+// Prerrequisito indica que para matricularse en una asignatura hay que haber aprobado otra
+type Prerrequisito struct {
+	ID                    string `json:"id_"`
+	IDPrerrequisito       string `json:"id_prerrequisitos"`
+	IDAsignatura          string `json:"id_asignaturas"`
+	IDAsignaturaRequerida string `json:"id_asignatura_requerida"`
+	Version               int    `json:"version"`
+	// Campos adicionales para consultas
+	NombreAsignatura string `json:"nombre_asignatura,omitempty"`
+	NombreRequerida  string `json:"nombre_requerida,omitempty"`
+}
+
+// PlanEstudios es el grafo de asignaturas: los nodos y las aristas de prerrequisitos
+type PlanEstudios struct {
+	Asignaturas    []Asignatura    `json:"asignaturas"`
+	Prerrequisitos []Prerrequisito `json:"prerrequisitos"`
+}
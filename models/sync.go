@@ -0,0 +1,21 @@
+package models
+
+import "encoding/json"
+
+// Resultados de aplicar un evento de sincronización recibido
+const (
+	SyncAplicado  = "aplicado"
+	SyncObsoleto  = "obsoleto"
+	SyncDuplicado = "duplicado"
+	SyncPropio    = "propio"
+)
+
+// EventoSync representa un cambio recibido desde otra réplica a través del middleware
+type EventoSync struct {
+	ID        string          `json:"id" validar:"requerido,max=64"`
+	Operacion string          `json:"operation" validar:"requerido,oneof=CREATE|UPDATE|DELETE"`
+	Tabla     string          `json:"table" validar:"requerido,max=64"`
+	Data      json.RawMessage `json:"data" validar:"requerido"`
+	Source    string          `json:"source" validar:"requerido,max=64"`
+	Timestamp string          `json:"timestamp"`
+}
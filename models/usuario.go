@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Usuario representa las credenciales de acceso al sistema
+type Usuario struct {
+	ID           string `json:"id_"`
+	IDUsuario    string `json:"id_usuarios"`
+	Usuario      string `json:"usuario"`
+	PasswordHash string `json:"-"`
+	Rol          string `json:"rol"`
+	// IDReferencia es el id_estudiantes o id_profesores asociado, vacío para personal administrativo
+	IDReferencia string `json:"id_referencia,omitempty"`
+	Version      int    `json:"version"`
+}
+
+// Sesion representa una sesión iniciada por un usuario
+type Sesion struct {
+	TokenHash string    `json:"-"`
+	IDUsuario string    `json:"id_usuarios"`
+	Expira    time.Time `json:"expira"`
+}
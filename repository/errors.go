@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"server_estudiantes/models"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+var (
+	// ErrNotFound indica que el registro solicitado no existe
+	ErrNotFound = errors.New("registro no encontrado")
+
+	// ErrVersionConflict indica que el registro fue modificado por otro cliente
+	ErrVersionConflict = errors.New("la versión del registro no coincide")
+
+	// ErrDuplicate indica que ya existe un registro con los mismos datos
+	ErrDuplicate = errors.New("registro duplicado")
+
+	// ErrInUse indica que el registro no se puede eliminar porque otros lo referencian
+	ErrInUse = errors.New("registro referenciado por otros registros")
+
+	// ErrMatriculaCerrada indica que el ciclo de la asignación está fuera de su período de matrícula
+	ErrMatriculaCerrada = errors.New("la matrícula del ciclo está cerrada")
+
+	// ErrAsignaturaRepetida indica que el estudiante ya está matriculado en la asignatura en otra sección del ciclo
+	ErrAsignaturaRepetida = errors.New("asignatura ya matriculada en el ciclo")
+
+	// ErrCupoAgotado indica que la asignación no tiene cupos disponibles
+	ErrCupoAgotado = errors.New("cupo agotado")
+
+	// ErrPrerrequisitos indica que el estudiante no aprobó los prerrequisitos de la asignatura
+	ErrPrerrequisitos = errors.New("prerrequisitos pendientes")
+
+	// ErrLimiteCreditos indica que la matrícula supera los créditos permitidos por ciclo
+	ErrLimiteCreditos = errors.New("límite de créditos del ciclo superado")
+
+	// ErrCicloPrerrequisitos indica que un prerrequisito nuevo cerraría un ciclo en el plan de estudios
+	ErrCicloPrerrequisitos = errors.New("el prerrequisito forma un ciclo")
+)
+
+// Nombres de las entidades reportadas en NotFoundError
+const (
+	EntidadEstudiante    = "estudiante"
+	EntidadProfesor      = "profesor"
+	EntidadAsignatura    = "asignatura"
+	EntidadPrerrequisito = "prerrequisito"
+	EntidadCiclo         = "ciclo"
+	EntidadAsignacion    = "asignacion"
+	EntidadMatricula     = "matricula"
+	EntidadNota          = "registro_notas"
+	EntidadUsuario       = "usuario"
+	EntidadSesion        = "sesion"
+	EntidadEvento        = "evento"
+)
+
+// NotFoundError indica qué entidad no existe, ya sea la solicitada o una referenciada
+type NotFoundError struct {
+	Entidad string
+	ID      string
+}
+
+func (e *NotFoundError) Error() string {
+	return e.Entidad + " " + e.ID + " no encontrado"
+}
+
+// Is permite comparar el error con ErrNotFound
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// VersionError indica qué registro tenía una versión distinta a la esperada
+type VersionError struct {
+	ID string
+}
+
+func (e *VersionError) Error() string {
+	return "la versión del registro " + e.ID + " no coincide"
+}
+
+// Is permite comparar el error con ErrVersionConflict
+func (e *VersionError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// PrerrequisitosError indica qué asignaturas requeridas aún no aprobó el estudiante
+type PrerrequisitosError struct {
+	Faltantes []models.Asignatura
+}
+
+func (e *PrerrequisitosError) Error() string {
+	nombres := make([]string, len(e.Faltantes))
+	for i, a := range e.Faltantes {
+		nombres[i] = a.Nombre
+	}
+	return "prerrequisitos pendientes: " + strings.Join(nombres, ", ")
+}
+
+// Is permite comparar el error con ErrPrerrequisitos
+func (e *PrerrequisitosError) Is(target error) bool {
+	return target == ErrPrerrequisitos
+}
+
+// LimiteCreditosError detalla los créditos de una matrícula que supera el límite del ciclo
+type LimiteCreditosError struct {
+	Maximo     int `json:"max_creditos"`
+	Inscritos  int `json:"creditos_inscritos"`
+	Asignatura int `json:"creditos_asignatura"`
+}
+
+func (e *LimiteCreditosError) Error() string {
+	return fmt.Sprintf("límite de créditos del ciclo superado: %d inscritos + %d > %d", e.Inscritos, e.Asignatura, e.Maximo)
+}
+
+// Is permite comparar el error con ErrLimiteCreditos
+func (e *LimiteCreditosError) Is(target error) bool {
+	return target == ErrLimiteCreditos
+}
+
+// CicloError indica el ciclo que cerraría un prerrequisito nuevo
+type CicloError struct {
+	// Ruta son los IDs de las asignaturas del ciclo; empieza y termina en la misma
+	Ruta []string
+}
+
+func (e *CicloError) Error() string {
+	return "el prerrequisito forma un ciclo: " + strings.Join(e.Ruta, " -> ")
+}
+
+// Is permite comparar el error con ErrCicloPrerrequisitos
+func (e *CicloError) Is(target error) bool {
+	return target == ErrCicloPrerrequisitos
+}
+
+// Códigos de error de MySQL para las restricciones del esquema
+const (
+	mysqlDuplicado        = 1062
+	mysqlFilaReferenciada = 1451
+	mysqlSinReferencia    = 1452
+)
+
+// restriccion traduce las violaciones de claves únicas y foráneas a los errores del repositorio
+func restriccion(err error) error {
+	var me *mysql.MySQLError
+	if !errors.As(err, &me) {
+		return err
+	}
+	switch me.Number {
+	case mysqlDuplicado:
+		return fmt.Errorf("%w: %s", ErrDuplicate, me.Message)
+	case mysqlFilaReferenciada:
+		return fmt.Errorf("%w: %s", ErrInUse, me.Message)
+	case mysqlSinReferencia:
+		return fmt.Errorf("%w: %s", ErrNotFound, me.Message)
+	}
+	return err
+}
+
+// notFound convierte sql.ErrNoRows en un NotFoundError de la entidad indicada
+func notFound(err error, entidad, id string) error {
+	if err == sql.ErrNoRows {
+		return &NotFoundError{Entidad: entidad, ID: id}
+	}
+	return err
+}
+
+// verificarVersion devuelve ErrVersionConflict si una sentencia condicionada por
+// la versión no afectó ninguna fila
+func verificarVersion(result sql.Result, id string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &VersionError{ID: id}
+	}
+	return nil
+}
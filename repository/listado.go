@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidParam indica un filtro, orden o posición no soportado por el listado
+var ErrInvalidParam = errors.New("parámetro de listado inválido")
+
+// Orden indica un campo por el que se ordena un listado
+type Orden struct {
+	Campo string
+	Desc  bool
+}
+
+// ListParams describe la paginación, los filtros y el orden de un listado.
+// Un Limit menor o igual a cero devuelve todos los registros.
+type ListParams struct {
+	Limit int
+	// Despues son los valores de orden y de desempate del último registro de la página
+	// anterior; la página empieza en el registro que le sigue, aunque entre páginas se
+	// hayan insertado o eliminado registros
+	Despues []string
+	Orden   []Orden
+	Filtros map[string]string
+}
+
+// campoListado asocia un campo público del listado con su columna SQL
+type campoListado struct {
+	columna string
+	// parcial indica que el filtro busca coincidencias parciales con LIKE
+	parcial bool
+}
+
+// listado describe los campos que un listado permite filtrar y ordenar
+type listado struct {
+	// base es la consulta SELECT del listado, sin WHERE ni ORDER BY
+	base   string
+	campos map[string]campoListado
+	// desempate es una columna única que, en orden ascendente, garantiza un orden estable
+	// entre páginas y completa la posición de cada registro
+	desempate string
+}
+
+// construir genera la consulta de la página y la de conteo con sus argumentos. La consulta
+// de la página antepone a las columnas de base las de orden, que paginar separa de cada
+// registro para obtener la posición de la página siguiente.
+func (l listado) construir(p ListParams) (query string, args []interface{}, countQuery string, countArgs []interface{}, err error) {
+	var condiciones []string
+	for campo, valor := range p.Filtros {
+		def, ok := l.campos[campo]
+		if !ok {
+			return "", nil, "", nil, fmt.Errorf("%w: no se puede filtrar por %s", ErrInvalidParam, campo)
+		}
+		if def.parcial {
+			condiciones = append(condiciones, def.columna+" LIKE ?")
+			countArgs = append(countArgs, "%"+valor+"%")
+		} else {
+			condiciones = append(condiciones, def.columna+" = ?")
+			countArgs = append(countArgs, valor)
+		}
+	}
+
+	where := ""
+	if len(condiciones) > 0 {
+		where = " WHERE " + strings.Join(condiciones, " AND ")
+	}
+	countQuery = "SELECT COUNT(*) FROM (" + l.base + where + ") t"
+
+	var columnas, orden []string
+	var desc []bool
+	for _, o := range p.Orden {
+		def, ok := l.campos[o.Campo]
+		if !ok {
+			return "", nil, "", nil, fmt.Errorf("%w: no se puede ordenar por %s", ErrInvalidParam, o.Campo)
+		}
+		columnas = append(columnas, def.columna)
+		desc = append(desc, o.Desc)
+		if o.Desc {
+			orden = append(orden, def.columna+" DESC")
+		} else {
+			orden = append(orden, def.columna+" ASC")
+		}
+	}
+	columnas = append(columnas, l.desempate)
+	desc = append(desc, false)
+	orden = append(orden, l.desempate+" ASC")
+
+	args = append(args, countArgs...)
+	if len(p.Despues) > 0 {
+		if len(p.Despues) != len(columnas) {
+			return "", nil, "", nil, fmt.Errorf("%w: la posición no corresponde al orden del listado", ErrInvalidParam)
+		}
+		condicion, valores := posterior(columnas, desc, p.Despues)
+		condiciones = append(condiciones, condicion)
+		args = append(args, valores...)
+		where = " WHERE " + strings.Join(condiciones, " AND ")
+	}
+
+	query = "SELECT " + strings.Join(columnas, ", ") + "," +
+		strings.TrimPrefix(strings.TrimSpace(l.base), "SELECT") +
+		where + " ORDER BY " + strings.Join(orden, ", ")
+	if p.Limit > 0 {
+		// El registro de más solo indica si existe una página siguiente
+		query += fmt.Sprintf(" LIMIT %d", p.Limit+1)
+	}
+	return query, args, countQuery, countArgs, nil
+}
+
+// posterior arma la condición de los registros que siguen a una posición en el orden de
+// las columnas: (c1 > v1) OR (c1 = v1 AND c2 > v2) OR ..., con < en las descendentes
+func posterior(columnas []string, desc []bool, valores []string) (string, []interface{}) {
+	var alternativas []string
+	var args []interface{}
+	for i := range columnas {
+		var partes []string
+		for j := 0; j < i; j++ {
+			partes = append(partes, columnas[j]+" = ?")
+			args = append(args, valores[j])
+		}
+		operador := " > ?"
+		if desc[i] {
+			operador = " < ?"
+		}
+		partes = append(partes, columnas[i]+operador)
+		args = append(args, valores[i])
+		alternativas = append(alternativas, "("+strings.Join(partes, " AND ")+")")
+	}
+	return "(" + strings.Join(alternativas, " OR ") + ")", args
+}
+
+// paginar ejecuta un listado y escanea cada registro con escanear. Devuelve el total de
+// registros que cumplen los filtros y la posición de la página siguiente, o nil si no hay más.
+func paginar[T any](ctx context.Context, q queryer, l listado, p ListParams, escanear func(row interface{ Scan(...interface{}) error }, v *T) error) ([]T, int, []string, error) {
+	query, args, countQuery, countArgs, err := l.construir(p)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	total, err := contar(ctx, q, countQuery, countArgs)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	defer rows.Close()
+
+	fila := &filaListado{rows: rows, claves: make([]sql.NullString, len(p.Orden)+1)}
+	registros := []T{}
+	var siguiente []string
+	for rows.Next() {
+		if p.Limit > 0 && len(registros) == p.Limit {
+			// La página siguiente empieza después del último registro escaneado
+			siguiente = fila.posicion()
+			break
+		}
+		var v T
+		if err := escanear(fila, &v); err != nil {
+			return nil, 0, nil, err
+		}
+		registros = append(registros, v)
+	}
+	return registros, total, siguiente, rows.Err()
+}
+
+// filaListado escanea un registro de la consulta de construir separando primero sus
+// valores de orden, que conserva hasta el siguiente registro
+type filaListado struct {
+	rows   *sql.Rows
+	claves []sql.NullString
+}
+
+func (f *filaListado) Scan(dest ...interface{}) error {
+	destinos := make([]interface{}, 0, len(f.claves)+len(dest))
+	for i := range f.claves {
+		destinos = append(destinos, &f.claves[i])
+	}
+	return f.rows.Scan(append(destinos, dest...)...)
+}
+
+// posicion devuelve los valores de orden del último registro escaneado
+func (f *filaListado) posicion() []string {
+	valores := make([]string, len(f.claves))
+	for i, c := range f.claves {
+		valores[i] = c.String
+	}
+	return valores
+}
+
+// contar ejecuta la consulta de conteo de un listado
+func contar(ctx context.Context, q queryer, countQuery string, args []interface{}) (int, error) {
+	var total int
+	err := q.QueryRowContext(ctx, countQuery, args...).Scan(&total)
+	return total, err
+}
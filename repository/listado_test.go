@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+var listadoPrueba = listado{
+	base: `
+		SELECT e.id_, e.nombre
+		FROM estudiantes e
+	`,
+	campos: map[string]campoListado{
+		"nombre":  {columna: "e.nombre", parcial: true},
+		"version": {columna: "e.version"},
+	},
+	desempate: "e.id_",
+}
+
+func TestListadoConstruir(t *testing.T) {
+	const conteo = "SELECT COUNT(*) FROM (" + `
+		SELECT e.id_, e.nombre
+		FROM estudiantes e
+	`
+
+	casos := []struct {
+		nombre     string
+		p          ListParams
+		query      string
+		args       []interface{}
+		countQuery string
+		countArgs  []interface{}
+	}{
+		{
+			nombre:     "sin parámetros",
+			p:          ListParams{},
+			query:      "SELECT e.id_, e.id_, e.nombre\n\t\tFROM estudiantes e ORDER BY e.id_ ASC",
+			countQuery: conteo + ") t",
+		},
+		{
+			nombre:     "filtro parcial y límite",
+			p:          ListParams{Limit: 10, Filtros: map[string]string{"nombre": "an"}},
+			query:      "SELECT e.id_, e.id_, e.nombre\n\t\tFROM estudiantes e WHERE e.nombre LIKE ? ORDER BY e.id_ ASC LIMIT 11",
+			args:       []interface{}{"%an%"},
+			countQuery: conteo + " WHERE e.nombre LIKE ?) t",
+			countArgs:  []interface{}{"%an%"},
+		},
+		{
+			nombre: "posición con orden descendente",
+			p: ListParams{
+				Limit:   5,
+				Despues: []string{"Ana", "42"},
+				Orden:   []Orden{{Campo: "nombre", Desc: true}},
+				Filtros: map[string]string{"version": "2"},
+			},
+			query: "SELECT e.nombre, e.id_, e.id_, e.nombre\n\t\tFROM estudiantes e" +
+				" WHERE e.version = ? AND ((e.nombre < ?) OR (e.nombre = ? AND e.id_ > ?))" +
+				" ORDER BY e.nombre DESC, e.id_ ASC LIMIT 6",
+			args:       []interface{}{"2", "Ana", "Ana", "42"},
+			countQuery: conteo + " WHERE e.version = ?) t",
+			countArgs:  []interface{}{"2"},
+		},
+		{
+			nombre: "posición con dos columnas de orden",
+			p: ListParams{
+				Despues: []string{"Ana", "3", "42"},
+				Orden:   []Orden{{Campo: "nombre"}, {Campo: "version", Desc: true}},
+			},
+			query: "SELECT e.nombre, e.version, e.id_, e.id_, e.nombre\n\t\tFROM estudiantes e" +
+				" WHERE ((e.nombre > ?) OR (e.nombre = ? AND e.version < ?) OR (e.nombre = ? AND e.version = ? AND e.id_ > ?))" +
+				" ORDER BY e.nombre ASC, e.version DESC, e.id_ ASC",
+			args:       []interface{}{"Ana", "Ana", "3", "Ana", "3", "42"},
+			countQuery: conteo + ") t",
+		},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			query, args, countQuery, countArgs, err := listadoPrueba.construir(c.p)
+			if err != nil {
+				t.Fatalf("error inesperado: %v", err)
+			}
+			if query != c.query {
+				t.Errorf("query = %q, se esperaba %q", query, c.query)
+			}
+			if !reflect.DeepEqual(args, c.args) {
+				t.Errorf("args = %v, se esperaba %v", args, c.args)
+			}
+			if countQuery != c.countQuery {
+				t.Errorf("countQuery = %q, se esperaba %q", countQuery, c.countQuery)
+			}
+			if !reflect.DeepEqual(countArgs, c.countArgs) {
+				t.Errorf("countArgs = %v, se esperaba %v", countArgs, c.countArgs)
+			}
+		})
+	}
+}
+
+func TestListadoConstruirInvalido(t *testing.T) {
+	casos := []struct {
+		nombre string
+		p      ListParams
+	}{
+		{"filtro desconocido", ListParams{Filtros: map[string]string{"password": "x"}}},
+		{"orden desconocido", ListParams{Orden: []Orden{{Campo: "id_"}}}},
+		{"posición sin desempate", ListParams{Despues: []string{"Ana"}, Orden: []Orden{{Campo: "nombre"}}}},
+		{"posición de otro orden", ListParams{Despues: []string{"Ana", "42"}}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			if _, _, _, _, err := listadoPrueba.construir(c.p); !errors.Is(err, ErrInvalidParam) {
+				t.Errorf("err = %v, se esperaba ErrInvalidParam", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+	"time"
+)
+
+// selectAsignaciones incluye los nombres del profesor, la asignatura y el ciclo
+const selectAsignaciones = `
+	SELECT
+		pca.id_,
+		pca.id_profesores_ciclos_asignaturas,
+		pca.id_profesores,
+		pca.id_asignaturas,
+		pca.id_ciclos,
+		pca.cupo,
+		pca.version,
+		p.nombre AS nombre_profesor,
+		a.nombre_asignatura,
+		a.creditos,
+		a.horas_semanales,
+		c.ciclo
+	FROM profesores_ciclos_asignaturas pca
+	JOIN profesores p ON pca.id_profesores = p.id_profesores
+	JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
+	JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
+`
+
+// selectAsignacionBase lee solo la fila de profesores_ciclos_asignaturas
+const selectAsignacionBase = "SELECT id_, id_profesores_ciclos_asignaturas, id_profesores, id_asignaturas, id_ciclos, cupo, version FROM profesores_ciclos_asignaturas"
+
+// listadoAsignaciones define los filtros y el orden permitidos en el listado de asignaciones
+var listadoAsignaciones = listado{
+	base: selectAsignaciones,
+	campos: map[string]campoListado{
+		"id_profesores":     {columna: "pca.id_profesores"},
+		"id_asignaturas":    {columna: "pca.id_asignaturas"},
+		"id_ciclos":         {columna: "pca.id_ciclos"},
+		"nombre_profesor":   {columna: "p.nombre", parcial: true},
+		"nombre_asignatura": {columna: "a.nombre_asignatura", parcial: true},
+		"ciclo":             {columna: "c.ciclo"},
+	},
+	desempate: "pca.id_",
+}
+
+// mysqlAsignaciones implementa AsignacionRepo sobre MySQL
+type mysqlAsignaciones struct {
+	db *sql.DB
+	// politica decide qué asignaturas aprobó un estudiante al calcular las disponibles
+	politica config.PoliticaCalificacion
+}
+
+func scanAsignacion(row interface{ Scan(...interface{}) error }, a *models.Asignacion) error {
+	return row.Scan(
+		&a.ID,
+		&a.IDAsignacion,
+		&a.IDProfesor,
+		&a.IDAsignatura,
+		&a.IDCiclo,
+		&a.Cupo,
+		&a.Version,
+		&a.NombreProfesor,
+		&a.NombreAsignatura,
+		&a.Creditos,
+		&a.HorasSemanales,
+		&a.Ciclo,
+	)
+}
+
+// getAsignacionBase obtiene la fila de una asignación, opcionalmente bloqueándola
+func getAsignacionBase(ctx context.Context, q queryer, id string, bloquear bool) (models.Asignacion, error) {
+	query := selectAsignacionBase + " WHERE id_profesores_ciclos_asignaturas = ?"
+	if bloquear {
+		query += " FOR UPDATE"
+	}
+	var a models.Asignacion
+	err := q.QueryRowContext(ctx, query, id).
+		Scan(&a.ID, &a.IDAsignacion, &a.IDProfesor, &a.IDAsignatura, &a.IDCiclo, &a.Cupo, &a.Version)
+	return a, notFound(err, EntidadAsignacion, id)
+}
+
+func (r *mysqlAsignaciones) List(ctx context.Context, p ListParams) ([]models.Asignacion, int, []string, error) {
+	return paginar(ctx, r.db, listadoAsignaciones, p, scanAsignacion)
+}
+
+func (r *mysqlAsignaciones) Get(ctx context.Context, id string) (models.Asignacion, error) {
+	var a models.Asignacion
+	err := scanAsignacion(r.db.QueryRowContext(ctx, selectAsignaciones+" WHERE pca.id_profesores_ciclos_asignaturas = ?", id), &a)
+	return a, notFound(err, EntidadAsignacion, id)
+}
+
+func (r *mysqlAsignaciones) Create(ctx context.Context, a *models.Asignacion) error {
+	profesor, err := getProfesor(ctx, r.db, a.IDProfesor, false)
+	if err != nil {
+		return err
+	}
+	asignatura, err := getAsignatura(ctx, r.db, a.IDAsignatura, false)
+	if err != nil {
+		return err
+	}
+	ciclo, err := getCiclo(ctx, r.db, a.IDCiclo, false)
+	if err != nil {
+		return err
+	}
+
+	id, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+	idAsignacion, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO profesores_ciclos_asignaturas (id_, id_profesores_ciclos_asignaturas, id_profesores, id_asignaturas, id_ciclos, cupo, version) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			id, idAsignacion, a.IDProfesor, a.IDAsignatura, a.IDCiclo, a.Cupo, 1,
+		)
+		if err != nil {
+			return err
+		}
+
+		a.ID, a.IDAsignacion, a.Version = id, idAsignacion, 1
+		a.NombreProfesor, a.NombreAsignatura, a.Ciclo = profesor.Nombre, asignatura.Nombre, ciclo.Ciclo
+		return encolar(ctx, tx, models.OperacionCrear, TablaAsignaciones, a.IDAsignacion, *a)
+	})
+}
+
+func (r *mysqlAsignaciones) Update(ctx context.Context, a *models.Asignacion) error {
+	profesor, err := getProfesor(ctx, r.db, a.IDProfesor, false)
+	if err != nil {
+		return err
+	}
+
+	// La clave única (profesor, asignatura, ciclo) rechaza asignaciones duplicadas
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE profesores_ciclos_asignaturas SET id_profesores = ?, cupo = ?, version = ? WHERE id_profesores_ciclos_asignaturas = ? AND version = ?",
+			a.IDProfesor, a.Cupo, a.Version+1, a.IDAsignacion, a.Version,
+		)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, a.IDAsignacion); err != nil {
+			return err
+		}
+		a.Version++
+		a.NombreProfesor = profesor.Nombre
+		return encolar(ctx, tx, models.OperacionActualizar, TablaAsignaciones, a.IDAsignacion, *a)
+	})
+}
+
+func (r *mysqlAsignaciones) Delete(ctx context.Context, a models.Asignacion) error {
+	// La clave foránea de matriculas impide borrar una asignación con matrículas
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM profesores_ciclos_asignaturas WHERE id_profesores_ciclos_asignaturas = ? AND version = ?", a.IDAsignacion, a.Version)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, a.IDAsignacion); err != nil {
+			return err
+		}
+		return encolar(ctx, tx, models.OperacionEliminar, TablaAsignaciones, a.IDAsignacion, a)
+	})
+}
+
+func (r *mysqlAsignaciones) Disponibles(ctx context.Context, idEstudiante string) ([]models.AsignaturaDisponible, error) {
+	if _, err := getEstudiante(ctx, r.db, idEstudiante, false); err != nil {
+		return nil, err
+	}
+
+	aprobadas, enCurso, err := historialAsignaturas(ctx, r.db, r.politica, idEstudiante)
+	if err != nil {
+		return nil, err
+	}
+	grafo, err := grafoPrerrequisitos(ctx, r.db, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// La consulta descarta las asignaturas que el estudiante ya tiene matriculadas en el
+	// mismo ciclo, aunque sea en otra sección; las que cursa en otro ciclo se descartan
+	// después según la política de calificación
+	ahora := time.Now()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT
+			pca.id_profesores_ciclos_asignaturas,
+			pca.id_asignaturas,
+			a.nombre_asignatura,
+			a.creditos,
+			a.horas_semanales,
+			pca.id_profesores,
+			p.nombre,
+			pca.id_ciclos,
+			c.ciclo,
+			pca.cupo,
+			c.fin_matricula,
+			(SELECT COUNT(*) FROM matriculas mi WHERE mi.id_profesores_ciclos_asignaturas = pca.id_profesores_ciclos_asignaturas)
+		FROM profesores_ciclos_asignaturas pca
+		JOIN profesores p ON pca.id_profesores = p.id_profesores
+		JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
+		JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
+		WHERE (c.inicio_matricula IS NULL OR c.inicio_matricula <= ?)
+		AND (c.fin_matricula IS NULL OR c.fin_matricula >= ?)
+		AND NOT EXISTS (
+			SELECT 1
+			FROM matriculas m
+			JOIN profesores_ciclos_asignaturas pm ON m.id_profesores_ciclos_asignaturas = pm.id_profesores_ciclos_asignaturas
+			WHERE m.id_estudiantes = ? AND pm.id_asignaturas = pca.id_asignaturas
+			AND pm.id_ciclos = pca.id_ciclos
+		)
+		ORDER BY c.ciclo, a.nombre_asignatura, p.nombre
+	`, ahora, ahora, idEstudiante)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	disponibles := []models.AsignaturaDisponible{}
+	for rows.Next() {
+		var d models.AsignaturaDisponible
+		var inscritos int
+		err := rows.Scan(
+			&d.IDAsignacion,
+			&d.IDAsignatura,
+			&d.NombreAsignatura,
+			&d.Creditos,
+			&d.HorasSemanales,
+			&d.IDProfesor,
+			&d.NombreProfesor,
+			&d.IDCiclo,
+			&d.Ciclo,
+			&d.Cupo,
+			&d.FinMatricula,
+			&inscritos,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if aprobadas[d.IDAsignatura] || enCurso[d.IDAsignatura] || !cumplePrerrequisitos(grafo, aprobadas, d.IDAsignatura) {
+			continue
+		}
+		if d.Cupo != nil {
+			libres := *d.Cupo - inscritos
+			if libres <= 0 {
+				continue
+			}
+			d.CuposDisponibles = &libres
+		}
+		disponibles = append(disponibles, d)
+	}
+	return disponibles, rows.Err()
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+)
+
+const selectAsignaturas = "SELECT id_, id_asignaturas, nombre_asignatura, creditos, horas_semanales, version FROM asignaturas"
+
+// mysqlAsignaturas implementa AsignaturaRepo sobre MySQL
+type mysqlAsignaturas struct {
+	db *sql.DB
+}
+
+func scanAsignatura(row interface{ Scan(...interface{}) error }, a *models.Asignatura) error {
+	return row.Scan(&a.ID, &a.IDAsignatura, &a.Nombre, &a.Creditos, &a.HorasSemanales, &a.Version)
+}
+
+// getAsignatura obtiene una asignatura, opcionalmente bloqueándolo dentro de una transacción
+func getAsignatura(ctx context.Context, q queryer, id string, bloquear bool) (models.Asignatura, error) {
+	query := selectAsignaturas + " WHERE id_asignaturas = ?"
+	if bloquear {
+		query += " FOR UPDATE"
+	}
+	var a models.Asignatura
+	err := scanAsignatura(q.QueryRowContext(ctx, query, id), &a)
+	return a, notFound(err, EntidadAsignatura, id)
+}
+
+func (r *mysqlAsignaturas) List(ctx context.Context) ([]models.Asignatura, error) {
+	rows, err := r.db.QueryContext(ctx, selectAsignaturas)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	asignaturas := []models.Asignatura{}
+	for rows.Next() {
+		var a models.Asignatura
+		if err := scanAsignatura(rows, &a); err != nil {
+			return nil, err
+		}
+		asignaturas = append(asignaturas, a)
+	}
+	return asignaturas, rows.Err()
+}
+
+func (r *mysqlAsignaturas) Get(ctx context.Context, id string) (models.Asignatura, error) {
+	return getAsignatura(ctx, r.db, id, false)
+}
+
+func (r *mysqlAsignaturas) Create(ctx context.Context, a *models.Asignatura) error {
+	id, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+	idAsignatura, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO asignaturas (id_, id_asignaturas, nombre_asignatura, creditos, horas_semanales, version) VALUES (?, ?, ?, ?, ?, ?)",
+			id, idAsignatura, a.Nombre, a.Creditos, a.HorasSemanales, 1,
+		)
+		if err != nil {
+			return err
+		}
+
+		a.ID, a.IDAsignatura, a.Version = id, idAsignatura, 1
+		return encolar(ctx, tx, models.OperacionCrear, TablaAsignaturas, a.IDAsignatura, *a)
+	})
+}
+
+func (r *mysqlAsignaturas) Update(ctx context.Context, a *models.Asignatura) error {
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE asignaturas SET nombre_asignatura = ?, creditos = ?, horas_semanales = ?, version = ? WHERE id_asignaturas = ? AND version = ?",
+			a.Nombre, a.Creditos, a.HorasSemanales, a.Version+1, a.IDAsignatura, a.Version,
+		)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, a.IDAsignatura); err != nil {
+			return err
+		}
+		a.Version++
+		return encolar(ctx, tx, models.OperacionActualizar, TablaAsignaturas, a.IDAsignatura, *a)
+	})
+}
+
+func (r *mysqlAsignaturas) Delete(ctx context.Context, a models.Asignatura) error {
+	// Las claves foráneas de profesores_ciclos_asignaturas y de ambos lados de prerrequisitos
+	// impiden borrar una asignatura con asignaciones, con prerrequisitos o que otra exige
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM asignaturas WHERE id_asignaturas = ? AND version = ?", a.IDAsignatura, a.Version)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, a.IDAsignatura); err != nil {
+			return err
+		}
+		return encolar(ctx, tx, models.OperacionEliminar, TablaAsignaturas, a.IDAsignatura, a)
+	})
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+)
+
+const selectCiclos = "SELECT id_, id_ciclos, ciclo, inicio_matricula, fin_matricula, version FROM ciclos"
+
+// mysqlCiclos implementa CicloRepo sobre MySQL
+type mysqlCiclos struct {
+	db *sql.DB
+}
+
+func scanCiclo(row interface{ Scan(...interface{}) error }, c *models.Ciclo) error {
+	return row.Scan(&c.ID, &c.IDCiclo, &c.Ciclo, &c.InicioMatricula, &c.FinMatricula, &c.Version)
+}
+
+// getCiclo obtiene un ciclo, opcionalmente bloqueándolo dentro de una transacción
+func getCiclo(ctx context.Context, q queryer, id string, bloquear bool) (models.Ciclo, error) {
+	query := selectCiclos + " WHERE id_ciclos = ?"
+	if bloquear {
+		query += " FOR UPDATE"
+	}
+	var c models.Ciclo
+	err := scanCiclo(q.QueryRowContext(ctx, query, id), &c)
+	return c, notFound(err, EntidadCiclo, id)
+}
+
+func (r *mysqlCiclos) List(ctx context.Context) ([]models.Ciclo, error) {
+	rows, err := r.db.QueryContext(ctx, selectCiclos)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ciclos := []models.Ciclo{}
+	for rows.Next() {
+		var c models.Ciclo
+		if err := scanCiclo(rows, &c); err != nil {
+			return nil, err
+		}
+		ciclos = append(ciclos, c)
+	}
+	return ciclos, rows.Err()
+}
+
+func (r *mysqlCiclos) Get(ctx context.Context, id string) (models.Ciclo, error) {
+	return getCiclo(ctx, r.db, id, false)
+}
+
+func (r *mysqlCiclos) Create(ctx context.Context, c *models.Ciclo) error {
+	id, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+	idCiclo, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO ciclos (id_, id_ciclos, ciclo, inicio_matricula, fin_matricula, version) VALUES (?, ?, ?, ?, ?, ?)",
+			id, idCiclo, c.Ciclo, c.InicioMatricula, c.FinMatricula, 1,
+		)
+		if err != nil {
+			return err
+		}
+
+		c.ID, c.IDCiclo, c.Version = id, idCiclo, 1
+		return encolar(ctx, tx, models.OperacionCrear, TablaCiclos, c.IDCiclo, *c)
+	})
+}
+
+func (r *mysqlCiclos) Update(ctx context.Context, c *models.Ciclo) error {
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE ciclos SET ciclo = ?, inicio_matricula = ?, fin_matricula = ?, version = ? WHERE id_ciclos = ? AND version = ?",
+			c.Ciclo, c.InicioMatricula, c.FinMatricula, c.Version+1, c.IDCiclo, c.Version,
+		)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, c.IDCiclo); err != nil {
+			return err
+		}
+		c.Version++
+		return encolar(ctx, tx, models.OperacionActualizar, TablaCiclos, c.IDCiclo, *c)
+	})
+}
+
+func (r *mysqlCiclos) Delete(ctx context.Context, c models.Ciclo) error {
+	// La clave foránea de profesores_ciclos_asignaturas impide borrar un ciclo con asignaciones
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM ciclos WHERE id_ciclos = ? AND version = ?", c.IDCiclo, c.Version)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, c.IDCiclo); err != nil {
+			return err
+		}
+		return encolar(ctx, tx, models.OperacionEliminar, TablaCiclos, c.IDCiclo, c)
+	})
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"server_estudiantes/auth"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+)
+
+const selectEstudiantes = "SELECT id_, id_estudiantes, nombre, version FROM estudiantes"
+
+// listadoEstudiantes define los filtros y el orden permitidos en el listado de estudiantes
+var listadoEstudiantes = listado{
+	base: selectEstudiantes,
+	campos: map[string]campoListado{
+		"id_estudiantes": {columna: "id_estudiantes"},
+		"nombre":         {columna: "nombre", parcial: true},
+		"version":        {columna: "version"},
+	},
+	desempate: "id_",
+}
+
+// mysqlEstudiantes implementa EstudianteRepo sobre MySQL
+type mysqlEstudiantes struct {
+	db *sql.DB
+}
+
+func scanEstudiante(row interface{ Scan(...interface{}) error }, e *models.Estudiante) error {
+	return row.Scan(&e.ID, &e.IDEstudiante, &e.Nombre, &e.Version)
+}
+
+// getEstudiante obtiene un estudiante, opcionalmente bloqueándolo dentro de una transacción
+func getEstudiante(ctx context.Context, q queryer, id string, bloquear bool) (models.Estudiante, error) {
+	query := selectEstudiantes + " WHERE id_estudiantes = ?"
+	if bloquear {
+		query += " FOR UPDATE"
+	}
+	var e models.Estudiante
+	err := scanEstudiante(q.QueryRowContext(ctx, query, id), &e)
+	return e, notFound(err, EntidadEstudiante, id)
+}
+
+func (r *mysqlEstudiantes) List(ctx context.Context, p ListParams) ([]models.Estudiante, int, []string, error) {
+	return paginar(ctx, r.db, listadoEstudiantes, p, scanEstudiante)
+}
+
+func (r *mysqlEstudiantes) Get(ctx context.Context, id string) (models.Estudiante, error) {
+	return getEstudiante(ctx, r.db, id, false)
+}
+
+func (r *mysqlEstudiantes) Create(ctx context.Context, e *models.Estudiante) error {
+	id, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+	idEstudiante, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO estudiantes (id_, id_estudiantes, nombre, version) VALUES (?, ?, ?, ?)",
+			id, idEstudiante, e.Nombre, 1,
+		)
+		if err != nil {
+			return err
+		}
+
+		e.ID, e.IDEstudiante, e.Version = id, idEstudiante, 1
+		return encolar(ctx, tx, models.OperacionCrear, TablaEstudiantes, e.IDEstudiante, *e)
+	})
+}
+
+func (r *mysqlEstudiantes) Registrar(ctx context.Context, e *models.Estudiante, passwordHash string) (models.Usuario, error) {
+	id, err := config.GenerateID()
+	if err != nil {
+		return models.Usuario{}, err
+	}
+	idEstudiante, err := config.GenerateID()
+	if err != nil {
+		return models.Usuario{}, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Usuario{}, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO estudiantes (id_, id_estudiantes, nombre, version) VALUES (?, ?, ?, ?)",
+		id, idEstudiante, e.Nombre, 1,
+	)
+	if err != nil {
+		return models.Usuario{}, err
+	}
+
+	usuario := models.Usuario{
+		Usuario:      idEstudiante,
+		PasswordHash: passwordHash,
+		Rol:          auth.RolEstudiante,
+		IDReferencia: idEstudiante,
+	}
+	if err := insertUsuario(ctx, tx, &usuario); err != nil {
+		return models.Usuario{}, err
+	}
+
+	e.ID, e.IDEstudiante, e.Version = id, idEstudiante, 1
+	if err := encolar(ctx, tx, models.OperacionCrear, TablaEstudiantes, e.IDEstudiante, *e); err != nil {
+		return models.Usuario{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Usuario{}, err
+	}
+	return usuario, nil
+}
+
+func (r *mysqlEstudiantes) Update(ctx context.Context, e *models.Estudiante) error {
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE estudiantes SET nombre = ?, version = ? WHERE id_estudiantes = ? AND version = ?",
+			e.Nombre, e.Version+1, e.IDEstudiante, e.Version,
+		)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, e.IDEstudiante); err != nil {
+			return err
+		}
+		e.Version++
+		return encolar(ctx, tx, models.OperacionActualizar, TablaEstudiantes, e.IDEstudiante, *e)
+	})
+}
+
+func (r *mysqlEstudiantes) Delete(ctx context.Context, e models.Estudiante) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// La clave foránea de matriculas impide borrar un estudiante con matrículas
+	result, err := tx.ExecContext(ctx, "DELETE FROM estudiantes WHERE id_estudiantes = ? AND version = ?", e.IDEstudiante, e.Version)
+	if err != nil {
+		return restriccion(err)
+	}
+	if err := verificarVersion(result, e.IDEstudiante); err != nil {
+		return err
+	}
+
+	// Eliminar también las credenciales y sesiones del estudiante
+	_, err = tx.ExecContext(ctx, `
+		DELETE s FROM sesiones s
+		JOIN usuarios u ON s.id_usuarios = u.id_usuarios
+		WHERE u.rol = ? AND u.id_referencia = ?
+	`, auth.RolEstudiante, e.IDEstudiante)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "DELETE FROM usuarios WHERE rol = ? AND id_referencia = ?", auth.RolEstudiante, e.IDEstudiante)
+	if err != nil {
+		return err
+	}
+
+	if err := encolar(ctx, tx, models.OperacionEliminar, TablaEstudiantes, e.IDEstudiante, e); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
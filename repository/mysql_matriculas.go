@@ -0,0 +1,262 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+)
+
+// selectMatriculas incluye los nombres del estudiante, el profesor, la asignatura y el ciclo
+const selectMatriculas = `
+	SELECT
+		m.id_,
+		m.id_matriculas,
+		m.id_estudiantes,
+		m.id_profesores_ciclos_asignaturas,
+		m.version,
+		e.nombre AS nombre_estudiante,
+		p.nombre AS nombre_profesor,
+		a.nombre_asignatura,
+		a.creditos,
+		a.horas_semanales,
+		c.ciclo
+	FROM matriculas m
+	JOIN estudiantes e ON m.id_estudiantes = e.id_estudiantes
+	JOIN profesores_ciclos_asignaturas pca ON m.id_profesores_ciclos_asignaturas = pca.id_profesores_ciclos_asignaturas
+	JOIN profesores p ON pca.id_profesores = p.id_profesores
+	JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
+	JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
+`
+
+// listadoMatriculas define los filtros y el orden permitidos en el listado de matrículas
+var listadoMatriculas = listado{
+	base: selectMatriculas,
+	campos: map[string]campoListado{
+		"id_estudiantes":                   {columna: "m.id_estudiantes"},
+		"id_profesores_ciclos_asignaturas": {columna: "m.id_profesores_ciclos_asignaturas"},
+		"id_profesores":                    {columna: "pca.id_profesores"},
+		"id_asignaturas":                   {columna: "pca.id_asignaturas"},
+		"id_ciclos":                        {columna: "pca.id_ciclos"},
+		"ciclo":                            {columna: "c.ciclo"},
+		"nombre_estudiante":                {columna: "e.nombre", parcial: true},
+		"nombre_asignatura":                {columna: "a.nombre_asignatura", parcial: true},
+	},
+	desempate: "m.id_",
+}
+
+// mysqlMatriculas implementa MatriculaRepo sobre MySQL
+type mysqlMatriculas struct {
+	db *sql.DB
+	// politica decide qué registros de notas cuentan como prerrequisitos aprobados
+	politica config.PoliticaCalificacion
+	reglas   config.ReglasMatricula
+}
+
+func scanMatricula(row interface{ Scan(...interface{}) error }, m *models.Matricula) error {
+	return row.Scan(
+		&m.ID,
+		&m.IDMatricula,
+		&m.IDEstudiante,
+		&m.IDAsignacion,
+		&m.Version,
+		&m.NombreEstudiante,
+		&m.NombreProfesor,
+		&m.NombreAsignatura,
+		&m.Creditos,
+		&m.HorasSemanales,
+		&m.Ciclo,
+	)
+}
+
+func (r *mysqlMatriculas) list(ctx context.Context, query string, args ...interface{}) ([]models.Matricula, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	matriculas := []models.Matricula{}
+	for rows.Next() {
+		var m models.Matricula
+		if err := scanMatricula(rows, &m); err != nil {
+			return nil, err
+		}
+		matriculas = append(matriculas, m)
+	}
+	return matriculas, rows.Err()
+}
+
+func (r *mysqlMatriculas) List(ctx context.Context, p ListParams) ([]models.Matricula, int, []string, error) {
+	return paginar(ctx, r.db, listadoMatriculas, p, scanMatricula)
+}
+
+func (r *mysqlMatriculas) ListByEstudiante(ctx context.Context, idEstudiante string) ([]models.Matricula, error) {
+	if _, err := getEstudiante(ctx, r.db, idEstudiante, false); err != nil {
+		return nil, err
+	}
+	return r.list(ctx, selectMatriculas+" WHERE m.id_estudiantes = ? ORDER BY c.ciclo, a.nombre_asignatura", idEstudiante)
+}
+
+func (r *mysqlMatriculas) ListByAsignacion(ctx context.Context, idAsignacion string) ([]models.Matricula, error) {
+	if _, err := getAsignacionBase(ctx, r.db, idAsignacion, false); err != nil {
+		return nil, err
+	}
+	return r.list(ctx, selectMatriculas+" WHERE m.id_profesores_ciclos_asignaturas = ? ORDER BY e.nombre", idAsignacion)
+}
+
+func (r *mysqlMatriculas) Get(ctx context.Context, id string) (models.Matricula, error) {
+	var m models.Matricula
+	err := scanMatricula(r.db.QueryRowContext(ctx, selectMatriculas+" WHERE m.id_matriculas = ?", id), &m)
+	return m, notFound(err, EntidadMatricula, id)
+}
+
+func (r *mysqlMatriculas) Create(ctx context.Context, m *models.Matricula) (models.Nota, error) {
+	// Generar los IDs antes de abrir la transacción
+	ids := make([]string, 4)
+	for i := range ids {
+		var err error
+		if ids[i], err = config.GenerateID(); err != nil {
+			return models.Nota{}, err
+		}
+	}
+	id, idMatricula, idRegistro, idRegistroNotas := ids[0], ids[1], ids[2], ids[3]
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return models.Nota{}, err
+	}
+	defer tx.Rollback()
+
+	// Bloquear al estudiante serializa sus matrículas concurrentes
+	if _, err := getEstudiante(ctx, tx, m.IDEstudiante, true); err != nil {
+		return models.Nota{}, err
+	}
+	if err := verificarReglasMatricula(ctx, tx, r.politica, r.reglas, m); err != nil {
+		return models.Nota{}, err
+	}
+
+	// La clave única (estudiante, asignación) rechaza matrículas duplicadas
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO matriculas (id_, id_matriculas, id_estudiantes, id_profesores_ciclos_asignaturas, version) VALUES (?, ?, ?, ?, ?)",
+		id, idMatricula, m.IDEstudiante, m.IDAsignacion, 1,
+	)
+	if err != nil {
+		return models.Nota{}, restriccion(err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO registro_notas (id_, id_registro_notas, id_matriculas, nota1, nota2, sup, calificado, version) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		idRegistro, idRegistroNotas, idMatricula, 0, 0, 0, false, 1,
+	)
+	if err != nil {
+		return models.Nota{}, err
+	}
+
+	m.ID, m.IDMatricula, m.Version = id, idMatricula, 1
+	registro := models.Nota{
+		ID:          idRegistro,
+		IDNota:      idRegistroNotas,
+		IDMatricula: idMatricula,
+		Version:     1,
+	}
+	if err := encolar(ctx, tx, models.OperacionCrear, TablaMatriculas, m.IDMatricula, *m); err != nil {
+		return models.Nota{}, err
+	}
+	if err := encolar(ctx, tx, models.OperacionCrear, TablaNotas, registro.IDNota, registro); err != nil {
+		return models.Nota{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.Nota{}, err
+	}
+	return registro, nil
+}
+
+func (r *mysqlMatriculas) Update(ctx context.Context, m *models.Matricula) error {
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		var idEstudiante, idAsignacion string
+		err := tx.QueryRowContext(ctx,
+			"SELECT id_estudiantes, id_profesores_ciclos_asignaturas FROM matriculas WHERE id_matriculas = ? FOR UPDATE",
+			m.IDMatricula,
+		).Scan(&idEstudiante, &idAsignacion)
+		if err != nil {
+			return notFound(err, EntidadMatricula, m.IDMatricula)
+		}
+
+		if _, err := getEstudiante(ctx, tx, m.IDEstudiante, true); err != nil {
+			return err
+		}
+		// Las reglas solo se aplican a la nueva combinación; corregir otros datos de una
+		// matrícula existente no exige que el período siga abierto
+		if idEstudiante != m.IDEstudiante || idAsignacion != m.IDAsignacion {
+			if err := verificarReglasMatricula(ctx, tx, r.politica, r.reglas, m); err != nil {
+				return err
+			}
+		}
+
+		result, err := tx.ExecContext(ctx,
+			"UPDATE matriculas SET id_estudiantes = ?, id_profesores_ciclos_asignaturas = ?, version = ? WHERE id_matriculas = ? AND version = ?",
+			m.IDEstudiante, m.IDAsignacion, m.Version+1, m.IDMatricula, m.Version,
+		)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, m.IDMatricula); err != nil {
+			return err
+		}
+		m.Version++
+		return encolar(ctx, tx, models.OperacionActualizar, TablaMatriculas, m.IDMatricula, *m)
+	})
+}
+
+func (r *mysqlMatriculas) Delete(ctx context.Context, m models.Matricula) (*models.Nota, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Bloquear la matrícula y verificar que no cambió desde que el cliente la leyó
+	var version int
+	err = tx.QueryRowContext(ctx, "SELECT version FROM matriculas WHERE id_matriculas = ? FOR UPDATE", m.IDMatricula).Scan(&version)
+	if err != nil {
+		return nil, notFound(err, EntidadMatricula, m.IDMatricula)
+	}
+	if version != m.Version {
+		return nil, &VersionError{ID: m.IDMatricula}
+	}
+
+	// Obtener el registro de notas asociado
+	registro, err := getNotaBase(ctx, tx, "id_matriculas", m.IDMatricula, true)
+	tieneRegistro := err == nil
+	if err != nil && !isNotFound(err) {
+		return nil, err
+	}
+
+	// Eliminar primero el registro de notas (por la restricción de clave foránea)
+	if tieneRegistro {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM registro_notas WHERE id_matriculas = ?", m.IDMatricula); err != nil {
+			return nil, err
+		}
+		if err := encolar(ctx, tx, models.OperacionEliminar, TablaNotas, registro.IDNota, registro); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM matriculas WHERE id_matriculas = ?", m.IDMatricula); err != nil {
+		return nil, err
+	}
+	if err := encolar(ctx, tx, models.OperacionEliminar, TablaMatriculas, m.IDMatricula, m); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if !tieneRegistro {
+		return nil, nil
+	}
+	return &registro, nil
+}
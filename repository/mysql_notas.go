@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"server_estudiantes/models"
+)
+
+// selectNotas incluye los IDs del estudiante, la asignación y el profesor, y los nombres relacionados
+const selectNotas = `
+	SELECT
+		rn.id_,
+		rn.id_registro_notas,
+		rn.id_matriculas,
+		rn.nota1,
+		rn.nota2,
+		rn.sup,
+		rn.calificado,
+		rn.version,
+		m.id_estudiantes,
+		m.id_profesores_ciclos_asignaturas,
+		pca.id_profesores,
+		pca.id_asignaturas,
+		pca.id_ciclos,
+		e.nombre AS nombre_estudiante,
+		p.nombre AS nombre_profesor,
+		a.nombre_asignatura,
+		a.creditos,
+		c.ciclo
+	FROM registro_notas rn
+	JOIN matriculas m ON rn.id_matriculas = m.id_matriculas
+	JOIN estudiantes e ON m.id_estudiantes = e.id_estudiantes
+	JOIN profesores_ciclos_asignaturas pca ON m.id_profesores_ciclos_asignaturas = pca.id_profesores_ciclos_asignaturas
+	JOIN profesores p ON pca.id_profesores = p.id_profesores
+	JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
+	JOIN ciclos c ON pca.id_ciclos = c.id_ciclos
+`
+
+// listadoNotas define los filtros y el orden permitidos en el listado de notas
+var listadoNotas = listado{
+	base: selectNotas,
+	campos: map[string]campoListado{
+		"id_matriculas":                    {columna: "rn.id_matriculas"},
+		"id_estudiantes":                   {columna: "m.id_estudiantes"},
+		"id_profesores_ciclos_asignaturas": {columna: "m.id_profesores_ciclos_asignaturas"},
+		"id_profesores":                    {columna: "pca.id_profesores"},
+		"id_asignaturas":                   {columna: "pca.id_asignaturas"},
+		"id_ciclos":                        {columna: "pca.id_ciclos"},
+		"ciclo":                            {columna: "c.ciclo"},
+		"nombre_estudiante":                {columna: "e.nombre", parcial: true},
+		"nombre_asignatura":                {columna: "a.nombre_asignatura", parcial: true},
+		"nota1":                            {columna: "rn.nota1"},
+		"nota2":                            {columna: "rn.nota2"},
+	},
+	desempate: "rn.id_",
+}
+
+// mysqlNotas implementa NotaRepo sobre MySQL
+type mysqlNotas struct {
+	db *sql.DB
+}
+
+func scanNota(row interface{ Scan(...interface{}) error }, n *models.Nota) error {
+	return row.Scan(
+		&n.ID,
+		&n.IDNota,
+		&n.IDMatricula,
+		&n.Nota1,
+		&n.Nota2,
+		&n.Sup,
+		&n.Calificado,
+		&n.Version,
+		&n.IDEstudiante,
+		&n.IDAsignacion,
+		&n.IDProfesor,
+		&n.IDAsignatura,
+		&n.IDCiclo,
+		&n.NombreEstudiante,
+		&n.NombreProfesor,
+		&n.NombreAsignatura,
+		&n.Creditos,
+		&n.Ciclo,
+	)
+}
+
+// getNotaBase obtiene la fila de registro_notas buscando por la columna indicada
+func getNotaBase(ctx context.Context, q queryer, columna, valor string, bloquear bool) (models.Nota, error) {
+	query := "SELECT id_, id_registro_notas, id_matriculas, nota1, nota2, sup, calificado, version FROM registro_notas WHERE " + columna + " = ?"
+	if bloquear {
+		query += " FOR UPDATE"
+	}
+	var n models.Nota
+	err := q.QueryRowContext(ctx, query, valor).
+		Scan(&n.ID, &n.IDNota, &n.IDMatricula, &n.Nota1, &n.Nota2, &n.Sup, &n.Calificado, &n.Version)
+	return n, notFound(err, EntidadNota, valor)
+}
+
+// isNotFound indica si el error corresponde a un registro inexistente
+func isNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+func (r *mysqlNotas) list(ctx context.Context, query string, args ...interface{}) ([]models.Nota, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notas := []models.Nota{}
+	for rows.Next() {
+		var n models.Nota
+		if err := scanNota(rows, &n); err != nil {
+			return nil, err
+		}
+		notas = append(notas, n)
+	}
+	return notas, rows.Err()
+}
+
+func (r *mysqlNotas) List(ctx context.Context, p ListParams) ([]models.Nota, int, []string, error) {
+	return paginar(ctx, r.db, listadoNotas, p, scanNota)
+}
+
+func (r *mysqlNotas) Get(ctx context.Context, id string) (models.Nota, error) {
+	var n models.Nota
+	err := scanNota(r.db.QueryRowContext(ctx, selectNotas+" WHERE rn.id_registro_notas = ?", id), &n)
+	return n, notFound(err, EntidadNota, id)
+}
+
+func (r *mysqlNotas) ListByEstudiante(ctx context.Context, idEstudiante string) ([]models.Nota, error) {
+	if _, err := getEstudiante(ctx, r.db, idEstudiante, false); err != nil {
+		return nil, err
+	}
+	return r.list(ctx, selectNotas+" WHERE e.id_estudiantes = ?", idEstudiante)
+}
+
+func (r *mysqlNotas) Update(ctx context.Context, n *models.Nota) error {
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE registro_notas SET nota1 = ?, nota2 = ?, sup = ?, calificado = ?, version = ? WHERE id_registro_notas = ? AND version = ?",
+			n.Nota1, n.Nota2, n.Sup, n.Calificado, n.Version+1, n.IDNota, n.Version,
+		)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, n.IDNota); err != nil {
+			return err
+		}
+		n.Version++
+		return encolar(ctx, tx, models.OperacionActualizar, TablaNotas, n.IDNota, *n)
+	})
+}
+
+func (r *mysqlNotas) UpdateByAsignacion(ctx context.Context, idAsignacion string, notas []models.Nota) error {
+	if _, err := getAsignacionBase(ctx, r.db, idAsignacion, false); err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range notas {
+		n := &notas[i]
+		var version int
+		err := tx.QueryRowContext(ctx, `
+			SELECT rn.id_, rn.id_matriculas, m.id_estudiantes, rn.version
+			FROM registro_notas rn
+			JOIN matriculas m ON rn.id_matriculas = m.id_matriculas
+			WHERE rn.id_registro_notas = ? AND m.id_profesores_ciclos_asignaturas = ?
+			FOR UPDATE
+		`, n.IDNota, idAsignacion).Scan(&n.ID, &n.IDMatricula, &n.IDEstudiante, &version)
+		if err != nil {
+			return notFound(err, EntidadNota, n.IDNota)
+		}
+
+		if version != n.Version {
+			return &VersionError{ID: n.IDNota}
+		}
+		n.IDAsignacion = idAsignacion
+
+		_, err = tx.ExecContext(ctx,
+			"UPDATE registro_notas SET nota1 = ?, nota2 = ?, sup = ?, calificado = ?, version = ? WHERE id_registro_notas = ?",
+			n.Nota1, n.Nota2, n.Sup, n.Calificado, n.Version+1, n.IDNota,
+		)
+		if err != nil {
+			return err
+		}
+		n.Version++
+
+		if err := encolar(ctx, tx, models.OperacionActualizar, TablaNotas, n.IDNota, *n); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+)
+
+// selectPrerrequisitos incluye los nombres de la asignatura y de la asignatura requerida
+const selectPrerrequisitos = `
+	SELECT
+		p.id_,
+		p.id_prerrequisitos,
+		p.id_asignaturas,
+		p.id_asignatura_requerida,
+		p.version,
+		a.nombre_asignatura,
+		r.nombre_asignatura AS nombre_requerida
+	FROM prerrequisitos p
+	JOIN asignaturas a ON p.id_asignaturas = a.id_asignaturas
+	JOIN asignaturas r ON p.id_asignatura_requerida = r.id_asignaturas
+`
+
+// mysqlPrerrequisitos implementa PrerrequisitoRepo sobre MySQL
+type mysqlPrerrequisitos struct {
+	db *sql.DB
+	// politica decide qué registros de notas cuentan como asignaturas aprobadas
+	politica config.PoliticaCalificacion
+}
+
+func scanPrerrequisito(row interface{ Scan(...interface{}) error }, p *models.Prerrequisito) error {
+	return row.Scan(
+		&p.ID,
+		&p.IDPrerrequisito,
+		&p.IDAsignatura,
+		&p.IDAsignaturaRequerida,
+		&p.Version,
+		&p.NombreAsignatura,
+		&p.NombreRequerida,
+	)
+}
+
+func (r *mysqlPrerrequisitos) list(ctx context.Context, query string, args ...interface{}) ([]models.Prerrequisito, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prerrequisitos := []models.Prerrequisito{}
+	for rows.Next() {
+		var p models.Prerrequisito
+		if err := scanPrerrequisito(rows, &p); err != nil {
+			return nil, err
+		}
+		prerrequisitos = append(prerrequisitos, p)
+	}
+	return prerrequisitos, rows.Err()
+}
+
+func (r *mysqlPrerrequisitos) List(ctx context.Context) ([]models.Prerrequisito, error) {
+	return r.list(ctx, selectPrerrequisitos+" ORDER BY a.nombre_asignatura, r.nombre_asignatura")
+}
+
+func (r *mysqlPrerrequisitos) ListByAsignatura(ctx context.Context, idAsignatura string) ([]models.Prerrequisito, error) {
+	if _, err := getAsignatura(ctx, r.db, idAsignatura, false); err != nil {
+		return nil, err
+	}
+	return r.list(ctx, selectPrerrequisitos+" WHERE p.id_asignaturas = ? ORDER BY r.nombre_asignatura", idAsignatura)
+}
+
+func (r *mysqlPrerrequisitos) Get(ctx context.Context, id string) (models.Prerrequisito, error) {
+	var p models.Prerrequisito
+	err := scanPrerrequisito(r.db.QueryRowContext(ctx, selectPrerrequisitos+" WHERE p.id_prerrequisitos = ?", id), &p)
+	return p, notFound(err, EntidadPrerrequisito, id)
+}
+
+func (r *mysqlPrerrequisitos) Create(ctx context.Context, p *models.Prerrequisito) error {
+	id, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+	idPrerrequisito, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		asignatura, err := getAsignatura(ctx, tx, p.IDAsignatura, false)
+		if err != nil {
+			return err
+		}
+		requerida, err := getAsignatura(ctx, tx, p.IDAsignaturaRequerida, false)
+		if err != nil {
+			return err
+		}
+
+		// Leer el grafo completo con bloqueo serializa las altas concurrentes que,
+		// cada una por separado, no cerrarían un ciclo pero juntas sí
+		grafo, err := grafoPrerrequisitos(ctx, tx, true)
+		if err != nil {
+			return err
+		}
+		if ruta := buscarRuta(grafo, p.IDAsignaturaRequerida, p.IDAsignatura); ruta != nil {
+			return &CicloError{Ruta: append([]string{p.IDAsignatura}, ruta...)}
+		}
+
+		// La clave única (asignatura, requerida) rechaza prerrequisitos duplicados
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO prerrequisitos (id_, id_prerrequisitos, id_asignaturas, id_asignatura_requerida, version) VALUES (?, ?, ?, ?, ?)",
+			id, idPrerrequisito, p.IDAsignatura, p.IDAsignaturaRequerida, 1,
+		)
+		if err != nil {
+			return restriccion(err)
+		}
+
+		p.ID, p.IDPrerrequisito, p.Version = id, idPrerrequisito, 1
+		p.NombreAsignatura, p.NombreRequerida = asignatura.Nombre, requerida.Nombre
+		return encolar(ctx, tx, models.OperacionCrear, TablaPrerrequisitos, p.IDPrerrequisito, *p)
+	})
+}
+
+func (r *mysqlPrerrequisitos) Delete(ctx context.Context, p models.Prerrequisito) error {
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM prerrequisitos WHERE id_prerrequisitos = ? AND version = ?", p.IDPrerrequisito, p.Version)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, p.IDPrerrequisito); err != nil {
+			return err
+		}
+		return encolar(ctx, tx, models.OperacionEliminar, TablaPrerrequisitos, p.IDPrerrequisito, p)
+	})
+}
+
+func (r *mysqlPrerrequisitos) Elegibles(ctx context.Context, idEstudiante string) ([]models.Asignatura, error) {
+	if _, err := getEstudiante(ctx, r.db, idEstudiante, false); err != nil {
+		return nil, err
+	}
+
+	aprobadas, err := asignaturasAprobadas(ctx, r.db, r.politica, idEstudiante)
+	if err != nil {
+		return nil, err
+	}
+	grafo, err := grafoPrerrequisitos(ctx, r.db, false)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, selectAsignaturas+" ORDER BY nombre_asignatura")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	elegibles := []models.Asignatura{}
+	for rows.Next() {
+		var a models.Asignatura
+		if err := scanAsignatura(rows, &a); err != nil {
+			return nil, err
+		}
+		if !aprobadas[a.IDAsignatura] && cumplePrerrequisitos(grafo, aprobadas, a.IDAsignatura) {
+			elegibles = append(elegibles, a)
+		}
+	}
+	return elegibles, rows.Err()
+}
+
+// grafoPrerrequisitos devuelve, por asignatura, los IDs de las asignaturas que requiere
+func grafoPrerrequisitos(ctx context.Context, q queryer, bloquear bool) (map[string][]string, error) {
+	query := "SELECT id_asignaturas, id_asignatura_requerida FROM prerrequisitos"
+	if bloquear {
+		query += " FOR UPDATE"
+	}
+	rows, err := q.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	grafo := map[string][]string{}
+	for rows.Next() {
+		var asignatura, requerida string
+		if err := rows.Scan(&asignatura, &requerida); err != nil {
+			return nil, err
+		}
+		grafo[asignatura] = append(grafo[asignatura], requerida)
+	}
+	return grafo, rows.Err()
+}
+
+// cumplePrerrequisitos indica si están aprobadas todas las asignaturas que exige una asignatura
+func cumplePrerrequisitos(grafo map[string][]string, aprobadas map[string]bool, idAsignatura string) bool {
+	for _, requerida := range grafo[idAsignatura] {
+		if !aprobadas[requerida] {
+			return false
+		}
+	}
+	return true
+}
+
+// buscarRuta devuelve los IDs de un camino de requisitos de desde hasta hasta, ambos
+// incluidos, o nil si no existe; desde == hasta es un camino de un solo elemento
+func buscarRuta(grafo map[string][]string, desde, hasta string) []string {
+	visitados := map[string]bool{}
+	var ruta []string
+	var recorrer func(actual string) bool
+	recorrer = func(actual string) bool {
+		ruta = append(ruta, actual)
+		if actual == hasta {
+			return true
+		}
+		visitados[actual] = true
+		for _, siguiente := range grafo[actual] {
+			if !visitados[siguiente] && recorrer(siguiente) {
+				return true
+			}
+		}
+		ruta = ruta[:len(ruta)-1]
+		return false
+	}
+	if recorrer(desde) {
+		return ruta
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+// grafoPrueba: cálculo II requiere cálculo I y física; cálculo I y física requieren álgebra
+var grafoPrueba = map[string][]string{
+	"calculo2": {"calculo1", "fisica"},
+	"calculo1": {"algebra"},
+	"fisica":   {"algebra"},
+}
+
+func TestBuscarRuta(t *testing.T) {
+	casos := []struct {
+		nombre       string
+		desde, hasta string
+		ruta         []string
+	}{
+		{"requisito directo", "calculo1", "algebra", []string{"calculo1", "algebra"}},
+		{"requisito indirecto", "calculo2", "algebra", []string{"calculo2", "calculo1", "algebra"}},
+		{"misma asignatura", "algebra", "algebra", []string{"algebra"}},
+		{"sentido contrario", "algebra", "calculo2", nil},
+		{"sin relación", "fisica", "calculo1", nil},
+		{"asignatura sin prerrequisitos", "historia", "algebra", nil},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			if got := buscarRuta(grafoPrueba, c.desde, c.hasta); !reflect.DeepEqual(got, c.ruta) {
+				t.Errorf("buscarRuta(%s, %s) = %v, se esperaba %v", c.desde, c.hasta, got, c.ruta)
+			}
+		})
+	}
+}
+
+// Un prerrequisito nuevo (asignatura, requerida) cierra un ciclo si la requerida ya
+// depende de la asignatura; Create lo rechaza con la ruta del ciclo
+func TestBuscarRutaDetectaCiclos(t *testing.T) {
+	casos := []struct {
+		asignatura, requerida string
+		ciclo                 bool
+	}{
+		{"algebra", "calculo2", true},
+		{"algebra", "algebra", true},
+		{"fisica", "calculo1", false},
+		{"historia", "calculo2", false},
+	}
+
+	for _, c := range casos {
+		ruta := buscarRuta(grafoPrueba, c.requerida, c.asignatura)
+		if (ruta != nil) != c.ciclo {
+			t.Errorf("prerrequisito %s -> %s: ruta %v, se esperaba ciclo = %v", c.asignatura, c.requerida, ruta, c.ciclo)
+		}
+		if ruta != nil && (ruta[0] != c.requerida || ruta[len(ruta)-1] != c.asignatura) {
+			t.Errorf("prerrequisito %s -> %s: la ruta %v no une ambas asignaturas", c.asignatura, c.requerida, ruta)
+		}
+	}
+}
+
+func TestCumplePrerrequisitos(t *testing.T) {
+	casos := []struct {
+		nombre     string
+		asignatura string
+		aprobadas  map[string]bool
+		cumple     bool
+	}{
+		{"sin prerrequisitos", "algebra", nil, true},
+		{"todos aprobados", "calculo2", map[string]bool{"calculo1": true, "fisica": true}, true},
+		{"falta uno", "calculo2", map[string]bool{"calculo1": true}, false},
+		{"solo aprobados indirectos", "calculo2", map[string]bool{"algebra": true}, false},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			if got := cumplePrerrequisitos(grafoPrueba, c.aprobadas, c.asignatura); got != c.cumple {
+				t.Errorf("cumplePrerrequisitos(%s) = %v, se esperaba %v", c.asignatura, got, c.cumple)
+			}
+		})
+	}
+}
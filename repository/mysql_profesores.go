@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+)
+
+const selectProfesores = "SELECT id_, id_profesores, nombre, version FROM profesores"
+
+// mysqlProfesores implementa ProfesorRepo sobre MySQL
+type mysqlProfesores struct {
+	db *sql.DB
+}
+
+func scanProfesor(row interface{ Scan(...interface{}) error }, p *models.Profesor) error {
+	return row.Scan(&p.ID, &p.IDProfesor, &p.Nombre, &p.Version)
+}
+
+// getProfesor obtiene un profesor, opcionalmente bloqueándolo dentro de una transacción
+func getProfesor(ctx context.Context, q queryer, id string, bloquear bool) (models.Profesor, error) {
+	query := selectProfesores + " WHERE id_profesores = ?"
+	if bloquear {
+		query += " FOR UPDATE"
+	}
+	var p models.Profesor
+	err := scanProfesor(q.QueryRowContext(ctx, query, id), &p)
+	return p, notFound(err, EntidadProfesor, id)
+}
+
+func (r *mysqlProfesores) List(ctx context.Context) ([]models.Profesor, error) {
+	rows, err := r.db.QueryContext(ctx, selectProfesores)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profesores := []models.Profesor{}
+	for rows.Next() {
+		var p models.Profesor
+		if err := scanProfesor(rows, &p); err != nil {
+			return nil, err
+		}
+		profesores = append(profesores, p)
+	}
+	return profesores, rows.Err()
+}
+
+func (r *mysqlProfesores) Get(ctx context.Context, id string) (models.Profesor, error) {
+	return getProfesor(ctx, r.db, id, false)
+}
+
+func (r *mysqlProfesores) Create(ctx context.Context, p *models.Profesor) error {
+	id, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+	idProfesor, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO profesores (id_, id_profesores, nombre, version) VALUES (?, ?, ?, ?)",
+			id, idProfesor, p.Nombre, 1,
+		)
+		if err != nil {
+			return err
+		}
+
+		p.ID, p.IDProfesor, p.Version = id, idProfesor, 1
+		return encolar(ctx, tx, models.OperacionCrear, TablaProfesores, p.IDProfesor, *p)
+	})
+}
+
+func (r *mysqlProfesores) Update(ctx context.Context, p *models.Profesor) error {
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx,
+			"UPDATE profesores SET nombre = ?, version = ? WHERE id_profesores = ? AND version = ?",
+			p.Nombre, p.Version+1, p.IDProfesor, p.Version,
+		)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, p.IDProfesor); err != nil {
+			return err
+		}
+		p.Version++
+		return encolar(ctx, tx, models.OperacionActualizar, TablaProfesores, p.IDProfesor, *p)
+	})
+}
+
+func (r *mysqlProfesores) Delete(ctx context.Context, p models.Profesor) error {
+	// La clave foránea de profesores_ciclos_asignaturas impide borrar un profesor con asignaciones
+	return enTransaccion(ctx, r.db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM profesores WHERE id_profesores = ? AND version = ?", p.IDProfesor, p.Version)
+		if err != nil {
+			return err
+		}
+		if err := verificarVersion(result, p.IDProfesor); err != nil {
+			return err
+		}
+		return encolar(ctx, tx, models.OperacionEliminar, TablaProfesores, p.IDProfesor, p)
+	})
+}
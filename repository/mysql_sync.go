@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"server_estudiantes/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tablaSync describe las columnas que se replican de una tabla; las claves del
+// objeto data coinciden con los nombres de las columnas
+type tablaSync struct {
+	clave    string
+	columnas []string
+	// nulables son las columnas que pueden llegar como null
+	nulables []string
+	// fechas son las columnas que llegan en formato RFC 3339
+	fechas []string
+}
+
+// tablasSync define las tablas que acepta el receptor de sincronización
+var tablasSync = map[string]tablaSync{
+	TablaEstudiantes: {clave: "id_estudiantes", columnas: []string{"id_", "id_estudiantes", "nombre"}},
+	TablaAsignaturas: {clave: "id_asignaturas", columnas: []string{"id_", "id_asignaturas", "nombre_asignatura", "creditos", "horas_semanales"}},
+	TablaProfesores:  {clave: "id_profesores", columnas: []string{"id_", "id_profesores", "nombre"}},
+	TablaCiclos: {
+		clave:    "id_ciclos",
+		columnas: []string{"id_", "id_ciclos", "ciclo", "inicio_matricula", "fin_matricula"},
+		nulables: []string{"inicio_matricula", "fin_matricula"},
+		fechas:   []string{"inicio_matricula", "fin_matricula"},
+	},
+	TablaAsignaciones: {
+		clave:    "id_profesores_ciclos_asignaturas",
+		columnas: []string{"id_", "id_profesores_ciclos_asignaturas", "id_profesores", "id_asignaturas", "id_ciclos", "cupo"},
+		nulables: []string{"cupo"},
+	},
+	TablaMatriculas: {clave: "id_matriculas", columnas: []string{"id_", "id_matriculas", "id_estudiantes", "id_profesores_ciclos_asignaturas"}},
+	TablaNotas:      {clave: "id_registro_notas", columnas: []string{"id_", "id_registro_notas", "id_matriculas", "nota1", "nota2", "sup", "calificado"}},
+	TablaPrerrequisitos: {
+		clave:    "id_prerrequisitos",
+		columnas: []string{"id_", "id_prerrequisitos", "id_asignaturas", "id_asignatura_requerida"},
+	},
+}
+
+// mysqlSync implementa SyncRepo sobre MySQL
+type mysqlSync struct {
+	db *sql.DB
+}
+
+// eventoInvalido construye un ErrInvalidParam con el detalle del problema
+func eventoInvalido(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidParam, fmt.Sprintf(format, args...))
+}
+
+func (r *mysqlSync) Aplicar(ctx context.Context, e models.EventoSync) (string, error) {
+	tabla, ok := tablasSync[e.Tabla]
+	if !ok {
+		return "", eventoInvalido("tabla %q no sincronizable", e.Tabla)
+	}
+	if e.Operacion != models.OperacionCrear && e.Operacion != models.OperacionActualizar && e.Operacion != models.OperacionEliminar {
+		return "", eventoInvalido("operación %q desconocida", e.Operacion)
+	}
+
+	data := map[string]interface{}{}
+	dec := json.NewDecoder(bytes.NewReader(e.Data))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		return "", eventoInvalido("data inválida: %v", err)
+	}
+
+	clave, _ := data[tabla.clave].(string)
+	if clave == "" {
+		return "", eventoInvalido("falta %s en data", tabla.clave)
+	}
+	version, err := versionSync(data["version"])
+	if err != nil {
+		return "", err
+	}
+	if e.Operacion != models.OperacionEliminar {
+		for _, c := range tabla.columnas {
+			if data[c], err = tabla.valor(c, data[c]); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	// Registrar el evento primero: si ya existe, se recibió antes y no se vuelve a aplicar
+	result, err := tx.ExecContext(ctx,
+		"INSERT IGNORE INTO sync_eventos (id_evento, origen, operacion, tabla, id_registro, recibido) VALUES (?, ?, ?, ?, ?, ?)",
+		e.ID, e.Source, e.Operacion, e.Tabla, clave, time.Now(),
+	)
+	if err != nil {
+		return "", err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return "", err
+	} else if n == 0 {
+		return models.SyncDuplicado, nil
+	}
+
+	// Versión local actual, bloqueando la fila si existe
+	var actual int
+	err = tx.QueryRowContext(ctx,
+		"SELECT version FROM "+e.Tabla+" WHERE "+tabla.clave+" = ? FOR UPDATE", clave,
+	).Scan(&actual)
+	existe := err == nil
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	resultado := models.SyncAplicado
+	switch {
+	case e.Operacion == models.OperacionEliminar:
+		// Un borrado hecho sobre la versión v solo gana si no hay cambios locales posteriores
+		if !existe {
+			break
+		}
+		if actual > version {
+			resultado = models.SyncObsoleto
+			break
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+e.Tabla+" WHERE "+tabla.clave+" = ?", clave); err != nil {
+			return "", err
+		}
+
+	case !existe:
+		// CREATE, o UPDATE de un registro que aún no llegó: se inserta completo
+		columnas := append(append([]string{}, tabla.columnas...), "version")
+		args := make([]interface{}, 0, len(columnas))
+		for _, c := range tabla.columnas {
+			args = append(args, data[c])
+		}
+		args = append(args, version)
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO "+e.Tabla+" ("+strings.Join(columnas, ", ")+") VALUES (?"+strings.Repeat(", ?", len(columnas)-1)+")",
+			args...,
+		)
+		if err != nil {
+			return "", err
+		}
+
+	case actual >= version:
+		// Gana la última escritura: la versión local es igual o más reciente
+		resultado = models.SyncObsoleto
+
+	default:
+		asignaciones := make([]string, 0, len(tabla.columnas))
+		args := make([]interface{}, 0, len(tabla.columnas)+1)
+		for _, c := range tabla.columnas {
+			if c == "id_" || c == tabla.clave {
+				continue
+			}
+			asignaciones = append(asignaciones, c+" = ?")
+			args = append(args, data[c])
+		}
+		asignaciones = append(asignaciones, "version = ?")
+		args = append(args, version, clave)
+		_, err := tx.ExecContext(ctx,
+			"UPDATE "+e.Tabla+" SET "+strings.Join(asignaciones, ", ")+" WHERE "+tabla.clave+" = ?",
+			args...,
+		)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return resultado, nil
+}
+
+// valor valida el valor recibido para una columna y lo convierte al tipo que se inserta
+func (t tablaSync) valor(columna string, v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		if contiene(t.nulables, columna) {
+			return nil, nil
+		}
+	case string:
+		if !contiene(t.fechas, columna) {
+			return v, nil
+		}
+		fecha, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, eventoInvalido("%s no es una fecha RFC 3339: %s", columna, v)
+		}
+		return fecha, nil
+	case json.Number, bool:
+		return v, nil
+	}
+	return nil, eventoInvalido("falta %s en data o no es un valor simple", columna)
+}
+
+// contiene indica si la lista incluye el valor
+func contiene(lista []string, valor string) bool {
+	for _, v := range lista {
+		if v == valor {
+			return true
+		}
+	}
+	return false
+}
+
+// versionSync convierte el campo version recibido en un entero positivo
+func versionSync(v interface{}) (int, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, eventoInvalido("falta version en data")
+	}
+	version, err := strconv.Atoi(n.String())
+	if err != nil || version <= 0 {
+		return 0, eventoInvalido("version inválida: %s", n)
+	}
+	return version, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+	"time"
+)
+
+const selectUsuarios = "SELECT id_, id_usuarios, usuario, password_hash, rol, COALESCE(id_referencia, ''), version FROM usuarios"
+
+// mysqlUsuarios implementa UsuarioRepo sobre MySQL
+type mysqlUsuarios struct {
+	db *sql.DB
+}
+
+func scanUsuario(row interface{ Scan(...interface{}) error }, u *models.Usuario) error {
+	return row.Scan(&u.ID, &u.IDUsuario, &u.Usuario, &u.PasswordHash, &u.Rol, &u.IDReferencia, &u.Version)
+}
+
+// insertUsuario genera los IDs del usuario y lo inserta con la conexión o transacción dada
+func insertUsuario(ctx context.Context, q queryer, u *models.Usuario) error {
+	id, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+	idUsuario, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+
+	var referencia interface{}
+	if u.IDReferencia != "" {
+		referencia = u.IDReferencia
+	}
+	_, err = q.ExecContext(ctx,
+		"INSERT INTO usuarios (id_, id_usuarios, usuario, password_hash, rol, id_referencia, version) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		id, idUsuario, u.Usuario, u.PasswordHash, u.Rol, referencia, 1,
+	)
+	if err != nil {
+		// La clave única de usuario rechaza nombres de usuario repetidos
+		return restriccion(err)
+	}
+
+	u.ID, u.IDUsuario, u.Version = id, idUsuario, 1
+	return nil
+}
+
+func (r *mysqlUsuarios) GetByUsuario(ctx context.Context, usuario string) (models.Usuario, error) {
+	var u models.Usuario
+	err := scanUsuario(r.db.QueryRowContext(ctx, selectUsuarios+" WHERE usuario = ?", usuario), &u)
+	return u, notFound(err, EntidadUsuario, usuario)
+}
+
+func (r *mysqlUsuarios) Create(ctx context.Context, u *models.Usuario) error {
+	return insertUsuario(ctx, r.db, u)
+}
+
+func (r *mysqlUsuarios) CreateSesion(ctx context.Context, s models.Sesion) error {
+	_, err := r.db.ExecContext(ctx,
+		"INSERT INTO sesiones (token_hash, id_usuarios, expira) VALUES (?, ?, ?)",
+		s.TokenHash, s.IDUsuario, s.Expira,
+	)
+	return err
+}
+
+func (r *mysqlUsuarios) GetSesion(ctx context.Context, tokenHash string) (models.Usuario, error) {
+	var u models.Usuario
+	err := scanUsuario(r.db.QueryRowContext(ctx, `
+		SELECT u.id_, u.id_usuarios, u.usuario, u.password_hash, u.rol, COALESCE(u.id_referencia, ''), u.version
+		FROM sesiones s
+		JOIN usuarios u ON s.id_usuarios = u.id_usuarios
+		WHERE s.token_hash = ? AND s.expira > ?
+	`, tokenHash, time.Now()), &u)
+	return u, notFound(err, EntidadSesion, "")
+}
+
+func (r *mysqlUsuarios) DeleteSesion(ctx context.Context, tokenHash string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM sesiones WHERE token_hash = ?", tokenHash)
+	return err
+}
+
+func (r *mysqlUsuarios) DeleteSesionesVencidas(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM sesiones WHERE expira <= ?", time.Now())
+	return err
+}
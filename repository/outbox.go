@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+	"time"
+)
+
+// Tablas sincronizadas con el middleware
+const (
+	TablaEstudiantes    = "estudiantes"
+	TablaAsignaturas    = "asignaturas"
+	TablaProfesores     = "profesores"
+	TablaCiclos         = "ciclos"
+	TablaAsignaciones   = "profesores_ciclos_asignaturas"
+	TablaMatriculas     = "matriculas"
+	TablaNotas          = "registro_notas"
+	TablaPrerrequisitos = "prerrequisitos"
+)
+
+const selectOutbox = `
+	SELECT id, id_evento, operacion, tabla, id_registro, payload, estado, intentos,
+		COALESCE(ultimo_error, ''), creado, proximo_intento
+	FROM outbox o
+`
+
+// mysqlOutbox implementa OutboxRepo sobre MySQL
+type mysqlOutbox struct {
+	db *sql.DB
+}
+
+func scanEvento(row interface{ Scan(...interface{}) error }, e *models.EventoOutbox) error {
+	var payload []byte
+	err := row.Scan(&e.ID, &e.IDEvento, &e.Operacion, &e.Tabla, &e.IDRegistro, &payload,
+		&e.Estado, &e.Intentos, &e.UltimoError, &e.Creado, &e.ProximoIntento)
+	e.Payload = payload
+	return err
+}
+
+// enTransaccion ejecuta fn dentro de una transacción y la confirma si fn no devuelve error;
+// las violaciones de restricciones del esquema se devuelven como errores del repositorio
+func enTransaccion(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return restriccion(err)
+	}
+	return tx.Commit()
+}
+
+// encolar registra un evento de sincronización en la tabla outbox; debe llamarse
+// dentro de la misma transacción que aplica el cambio
+func encolar(ctx context.Context, q queryer, operacion, tabla, idRegistro string, data interface{}) error {
+	idEvento, err := config.GenerateID()
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	ahora := time.Now()
+	_, err = q.ExecContext(ctx,
+		"INSERT INTO outbox (id_evento, operacion, tabla, id_registro, payload, estado, intentos, creado, proximo_intento) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		idEvento, operacion, tabla, idRegistro, payload, models.EventoPendiente, 0, ahora, ahora,
+	)
+	return err
+}
+
+func (r *mysqlOutbox) Pendientes(ctx context.Context, limite int) ([]models.EventoOutbox, error) {
+	// Solo el evento más antiguo pendiente de cada registro, para respetar el orden por tabla y fila;
+	// los eventos fallidos no bloquean a los posteriores
+	rows, err := r.db.QueryContext(ctx, selectOutbox+`
+		WHERE o.estado = ? AND o.proximo_intento <= ?
+		AND NOT EXISTS (
+			SELECT 1 FROM outbox p
+			WHERE p.tabla = o.tabla AND p.id_registro = o.id_registro
+			AND p.estado = ? AND p.id < o.id
+		)
+		ORDER BY o.id
+		LIMIT ?
+	`, models.EventoPendiente, time.Now(), models.EventoPendiente, limite)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	eventos := []models.EventoOutbox{}
+	for rows.Next() {
+		var e models.EventoOutbox
+		if err := scanEvento(rows, &e); err != nil {
+			return nil, err
+		}
+		eventos = append(eventos, e)
+	}
+	return eventos, rows.Err()
+}
+
+func (r *mysqlOutbox) MarcarEnviado(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE outbox SET estado = ?, intentos = intentos + 1, ultimo_error = NULL, enviado = ? WHERE id = ?",
+		models.EventoEnviado, time.Now(), id,
+	)
+	return err
+}
+
+func (r *mysqlOutbox) Reprogramar(ctx context.Context, id int64, proximo time.Time, causa string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE outbox SET intentos = intentos + 1, ultimo_error = ?, proximo_intento = ? WHERE id = ?",
+		causa, proximo, id,
+	)
+	return err
+}
+
+func (r *mysqlOutbox) MarcarFallido(ctx context.Context, id int64, causa string) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE outbox SET estado = ?, intentos = intentos + 1, ultimo_error = ? WHERE id = ?",
+		models.EventoFallido, causa, id,
+	)
+	return err
+}
+
+func (r *mysqlOutbox) ListFallidos(ctx context.Context) ([]models.EventoOutbox, error) {
+	rows, err := r.db.QueryContext(ctx, selectOutbox+" WHERE o.estado = ? ORDER BY o.id", models.EventoFallido)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	eventos := []models.EventoOutbox{}
+	for rows.Next() {
+		var e models.EventoOutbox
+		if err := scanEvento(rows, &e); err != nil {
+			return nil, err
+		}
+		eventos = append(eventos, e)
+	}
+	return eventos, rows.Err()
+}
+
+func (r *mysqlOutbox) Reintentar(ctx context.Context, idEvento string) error {
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE outbox SET estado = ?, intentos = 0, proximo_intento = ? WHERE id_evento = ? AND estado = ?",
+		models.EventoPendiente, time.Now(), idEvento, models.EventoFallido,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return &NotFoundError{Entidad: EntidadEvento, ID: idEvento}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+	"time"
+)
+
+// verificarReglasMatricula aplica las reglas de negocio de una matrícula dentro de la
+// transacción que la registra: período de matrícula del ciclo, una sola sección por
+// asignatura y ciclo, cupo de la asignación, créditos por ciclo y prerrequisitos
+// aprobados. Bloquea la asignación para que las matrículas concurrentes respeten el
+// cupo; el límite de créditos depende de que el llamador ya haya bloqueado al estudiante.
+func verificarReglasMatricula(ctx context.Context, tx *sql.Tx, politica config.PoliticaCalificacion, reglas config.ReglasMatricula, m *models.Matricula) error {
+	asignacion, err := getAsignacionBase(ctx, tx, m.IDAsignacion, true)
+	if err != nil {
+		return err
+	}
+
+	ciclo, err := getCiclo(ctx, tx, asignacion.IDCiclo, false)
+	if err != nil {
+		return err
+	}
+	ahora := time.Now()
+	if (ciclo.InicioMatricula != nil && ahora.Before(*ciclo.InicioMatricula)) ||
+		(ciclo.FinMatricula != nil && ahora.After(*ciclo.FinMatricula)) {
+		return ErrMatriculaCerrada
+	}
+
+	// Otra matrícula del estudiante en la misma asignatura y ciclo, en cualquier sección
+	var otra string
+	err = tx.QueryRowContext(ctx, `
+		SELECT m.id_profesores_ciclos_asignaturas
+		FROM matriculas m
+		JOIN profesores_ciclos_asignaturas pca ON m.id_profesores_ciclos_asignaturas = pca.id_profesores_ciclos_asignaturas
+		WHERE m.id_estudiantes = ? AND pca.id_asignaturas = ? AND pca.id_ciclos = ? AND m.id_matriculas != ?
+		LIMIT 1
+	`, m.IDEstudiante, asignacion.IDAsignatura, asignacion.IDCiclo, m.IDMatricula).Scan(&otra)
+	if err == nil {
+		if otra == m.IDAsignacion {
+			return ErrDuplicate
+		}
+		return ErrAsignaturaRepetida
+	} else if err != sql.ErrNoRows {
+		return err
+	}
+
+	if asignacion.Cupo != nil {
+		var inscritos int
+		err := tx.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM matriculas WHERE id_profesores_ciclos_asignaturas = ? AND id_matriculas != ?",
+			m.IDAsignacion, m.IDMatricula,
+		).Scan(&inscritos)
+		if err != nil {
+			return err
+		}
+		if inscritos >= *asignacion.Cupo {
+			return ErrCupoAgotado
+		}
+	}
+
+	// Créditos de las demás matrículas del estudiante en el ciclo más los de esta asignatura
+	if reglas.MaxCreditosCiclo > 0 {
+		asignatura, err := getAsignatura(ctx, tx, asignacion.IDAsignatura, false)
+		if err != nil {
+			return err
+		}
+		var inscritos int
+		err = tx.QueryRowContext(ctx, `
+			SELECT COALESCE(SUM(a.creditos), 0)
+			FROM matriculas m
+			JOIN profesores_ciclos_asignaturas pca ON m.id_profesores_ciclos_asignaturas = pca.id_profesores_ciclos_asignaturas
+			JOIN asignaturas a ON pca.id_asignaturas = a.id_asignaturas
+			WHERE m.id_estudiantes = ? AND pca.id_ciclos = ? AND m.id_matriculas != ?
+		`, m.IDEstudiante, asignacion.IDCiclo, m.IDMatricula).Scan(&inscritos)
+		if err != nil {
+			return err
+		}
+		if inscritos+asignatura.Creditos > reglas.MaxCreditosCiclo {
+			return &LimiteCreditosError{Maximo: reglas.MaxCreditosCiclo, Inscritos: inscritos, Asignatura: asignatura.Creditos}
+		}
+	}
+
+	return verificarPrerrequisitos(ctx, tx, politica, m.IDEstudiante, asignacion.IDAsignatura)
+}
+
+// verificarPrerrequisitos falla con PrerrequisitosError si el estudiante no aprobó
+// todas las asignaturas requeridas por la asignatura indicada
+func verificarPrerrequisitos(ctx context.Context, q queryer, politica config.PoliticaCalificacion, idEstudiante, idAsignatura string) error {
+	rows, err := q.QueryContext(ctx, `
+		SELECT a.id_, a.id_asignaturas, a.nombre_asignatura, a.creditos, a.horas_semanales, a.version
+		FROM prerrequisitos p
+		JOIN asignaturas a ON p.id_asignatura_requerida = a.id_asignaturas
+		WHERE p.id_asignaturas = ?
+		ORDER BY a.nombre_asignatura
+	`, idAsignatura)
+	if err != nil {
+		return err
+	}
+	var requeridas []models.Asignatura
+	for rows.Next() {
+		var a models.Asignatura
+		if err := scanAsignatura(rows, &a); err != nil {
+			rows.Close()
+			return err
+		}
+		requeridas = append(requeridas, a)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(requeridas) == 0 {
+		return nil
+	}
+
+	aprobadas, err := asignaturasAprobadas(ctx, q, politica, idEstudiante)
+	if err != nil {
+		return err
+	}
+	var faltantes []models.Asignatura
+	for _, a := range requeridas {
+		if !aprobadas[a.IDAsignatura] {
+			faltantes = append(faltantes, a)
+		}
+	}
+	if len(faltantes) > 0 {
+		return &PrerrequisitosError{Faltantes: faltantes}
+	}
+	return nil
+}
+
+// asignaturasAprobadas devuelve las asignaturas que el estudiante aprobó en cualquier ciclo
+func asignaturasAprobadas(ctx context.Context, q queryer, politica config.PoliticaCalificacion, idEstudiante string) (map[string]bool, error) {
+	aprobadas, _, err := historialAsignaturas(ctx, q, politica, idEstudiante)
+	return aprobadas, err
+}
+
+// historialAsignaturas evalúa los registros de notas del estudiante con la política de
+// calificación y devuelve las asignaturas que aprobó en cualquier ciclo y las que aún
+// cursa según config.Finalizado. Una matrícula sin registro de notas se evalúa como un
+// registro sin calificar.
+func historialAsignaturas(ctx context.Context, q queryer, politica config.PoliticaCalificacion, idEstudiante string) (aprobadas, enCurso map[string]bool, err error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT pca.id_asignaturas, COALESCE(rn.calificado, FALSE), COALESCE(rn.nota1, 0), COALESCE(rn.nota2, 0), COALESCE(rn.sup, 0)
+		FROM matriculas m
+		JOIN profesores_ciclos_asignaturas pca ON m.id_profesores_ciclos_asignaturas = pca.id_profesores_ciclos_asignaturas
+		LEFT JOIN registro_notas rn ON rn.id_matriculas = m.id_matriculas
+		WHERE m.id_estudiantes = ?
+	`, idEstudiante)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	aprobadas, enCurso = map[string]bool{}, map[string]bool{}
+	for rows.Next() {
+		var idAsignatura string
+		var calificado bool
+		var nota1, nota2 float64
+		var sup int
+		if err := rows.Scan(&idAsignatura, &calificado, &nota1, &nota2, &sup); err != nil {
+			return nil, nil, err
+		}
+		_, _, estado := politica.Evaluar(calificado, nota1, nota2, sup)
+		switch {
+		case estado == config.EstadoAprobado:
+			aprobadas[idAsignatura] = true
+		case !config.Finalizado(estado):
+			enCurso[idAsignatura] = true
+		}
+	}
+	return aprobadas, enCurso, rows.Err()
+}
@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"server_estudiantes/config"
+	"server_estudiantes/models"
+	"time"
+)
+
+// EstudianteRepo define el acceso a los datos de estudiantes
+type EstudianteRepo interface {
+	// List devuelve la página solicitada, el total de registros que cumplen los filtros
+	// y la posición de la página siguiente, nil en la última
+	List(ctx context.Context, p ListParams) ([]models.Estudiante, int, []string, error)
+	Get(ctx context.Context, id string) (models.Estudiante, error)
+	// Create genera los IDs y la versión inicial del estudiante
+	Create(ctx context.Context, e *models.Estudiante) error
+	// Registrar crea el estudiante y sus credenciales en una sola transacción;
+	// el nombre de usuario es el id_estudiantes generado
+	Registrar(ctx context.Context, e *models.Estudiante, passwordHash string) (models.Usuario, error)
+	// Update espera la versión actual en e.Version y la incrementa al guardar
+	Update(ctx context.Context, e *models.Estudiante) error
+	// Delete falla con ErrInUse si el estudiante tiene matrículas
+	Delete(ctx context.Context, e models.Estudiante) error
+}
+
+// AsignaturaRepo define el acceso a los datos de asignaturas
+type AsignaturaRepo interface {
+	List(ctx context.Context) ([]models.Asignatura, error)
+	Get(ctx context.Context, id string) (models.Asignatura, error)
+	Create(ctx context.Context, a *models.Asignatura) error
+	Update(ctx context.Context, a *models.Asignatura) error
+	// Delete falla con ErrInUse si la asignatura tiene asignaciones, prerrequisitos o es
+	// prerrequisito de otra
+	Delete(ctx context.Context, a models.Asignatura) error
+}
+
+// PrerrequisitoRepo define el acceso al grafo de prerrequisitos entre asignaturas
+type PrerrequisitoRepo interface {
+	// List devuelve todas las aristas del plan de estudios
+	List(ctx context.Context) ([]models.Prerrequisito, error)
+	// ListByAsignatura falla con ErrNotFound si la asignatura no existe
+	ListByAsignatura(ctx context.Context, idAsignatura string) ([]models.Prerrequisito, error)
+	Get(ctx context.Context, id string) (models.Prerrequisito, error)
+	// Create falla con ErrDuplicate si el prerrequisito ya existe y con un CicloError
+	// si la asignatura requerida depende, directa o indirectamente, de la asignatura
+	Create(ctx context.Context, p *models.Prerrequisito) error
+	Delete(ctx context.Context, p models.Prerrequisito) error
+	// Elegibles devuelve las asignaturas que el estudiante aún no aprobó y cuyos
+	// prerrequisitos ya aprobó; falla con ErrNotFound si el estudiante no existe
+	Elegibles(ctx context.Context, idEstudiante string) ([]models.Asignatura, error)
+}
+
+// ProfesorRepo define el acceso a los datos de profesores
+type ProfesorRepo interface {
+	List(ctx context.Context) ([]models.Profesor, error)
+	Get(ctx context.Context, id string) (models.Profesor, error)
+	Create(ctx context.Context, p *models.Profesor) error
+	Update(ctx context.Context, p *models.Profesor) error
+	// Delete falla con ErrInUse si el profesor tiene asignaciones
+	Delete(ctx context.Context, p models.Profesor) error
+}
+
+// CicloRepo define el acceso a los datos de ciclos
+type CicloRepo interface {
+	List(ctx context.Context) ([]models.Ciclo, error)
+	Get(ctx context.Context, id string) (models.Ciclo, error)
+	Create(ctx context.Context, c *models.Ciclo) error
+	Update(ctx context.Context, c *models.Ciclo) error
+	// Delete falla con ErrInUse si el ciclo tiene asignaciones
+	Delete(ctx context.Context, c models.Ciclo) error
+}
+
+// AsignacionRepo define el acceso a los datos de profesores_ciclos_asignaturas
+type AsignacionRepo interface {
+	List(ctx context.Context, p ListParams) ([]models.Asignacion, int, []string, error)
+	Get(ctx context.Context, id string) (models.Asignacion, error)
+	// Create verifica que existan el profesor, la asignatura y el ciclo y
+	// falla con ErrDuplicate si la combinación ya existe
+	Create(ctx context.Context, a *models.Asignacion) error
+	// Update reasigna el profesor y el cupo de la asignación; reducir el cupo no
+	// anula matrículas existentes, solo impide nuevas
+	Update(ctx context.Context, a *models.Asignacion) error
+	// Delete falla con ErrInUse si la asignación tiene matrículas
+	Delete(ctx context.Context, a models.Asignacion) error
+	// Disponibles devuelve las asignaciones en las que el estudiante puede matricularse:
+	// ciclo en período de matrícula, cupos libres, prerrequisitos aprobados y sin haber
+	// aprobado ni estar cursando la asignatura. Falla con ErrNotFound si el estudiante no existe
+	Disponibles(ctx context.Context, idEstudiante string) ([]models.AsignaturaDisponible, error)
+}
+
+// MatriculaRepo define el acceso a los datos de matrículas
+type MatriculaRepo interface {
+	List(ctx context.Context, p ListParams) ([]models.Matricula, int, []string, error)
+	Get(ctx context.Context, id string) (models.Matricula, error)
+	// ListByEstudiante falla con ErrNotFound si el estudiante no existe
+	ListByEstudiante(ctx context.Context, idEstudiante string) ([]models.Matricula, error)
+	// ListByAsignacion falla con ErrNotFound si la asignación no existe
+	ListByAsignacion(ctx context.Context, idAsignacion string) ([]models.Matricula, error)
+	// Create crea la matrícula y su registro de notas en una sola transacción. Falla con
+	// ErrMatriculaCerrada, ErrAsignaturaRepetida, ErrCupoAgotado, un LimiteCreditosError
+	// o un PrerrequisitosError si la matrícula no cumple las reglas del ciclo y la asignatura
+	Create(ctx context.Context, m *models.Matricula) (models.Nota, error)
+	// Update aplica las mismas reglas que Create cuando cambia el estudiante o la asignación
+	Update(ctx context.Context, m *models.Matricula) error
+	// Delete elimina la matrícula y devuelve el registro de notas eliminado, si existía
+	Delete(ctx context.Context, m models.Matricula) (*models.Nota, error)
+}
+
+// NotaRepo define el acceso a los datos de registro_notas
+type NotaRepo interface {
+	List(ctx context.Context, p ListParams) ([]models.Nota, int, []string, error)
+	Get(ctx context.Context, id string) (models.Nota, error)
+	ListByEstudiante(ctx context.Context, idEstudiante string) ([]models.Nota, error)
+	// Update guarda las notas si la versión en n.Version sigue siendo la actual
+	Update(ctx context.Context, n *models.Nota) error
+	// UpdateByAsignacion guarda en una sola transacción notas que deben pertenecer a la
+	// asignación y completa en cada una el estudiante y la asignación
+	UpdateByAsignacion(ctx context.Context, idAsignacion string, notas []models.Nota) error
+}
+
+// UsuarioRepo define el acceso a las credenciales y sesiones
+type UsuarioRepo interface {
+	GetByUsuario(ctx context.Context, usuario string) (models.Usuario, error)
+	// Create falla con ErrDuplicate si el nombre de usuario ya existe
+	Create(ctx context.Context, u *models.Usuario) error
+	CreateSesion(ctx context.Context, s models.Sesion) error
+	// GetSesion devuelve el usuario de una sesión vigente o ErrNotFound si no existe o expiró
+	GetSesion(ctx context.Context, tokenHash string) (models.Usuario, error)
+	DeleteSesion(ctx context.Context, tokenHash string) error
+	// DeleteSesionesVencidas elimina las sesiones cuya fecha de expiración ya pasó
+	DeleteSesionesVencidas(ctx context.Context) error
+}
+
+// OutboxRepo define el acceso a los eventos pendientes de enviar al middleware
+type OutboxRepo interface {
+	// Pendientes devuelve los eventos listos para enviar, como máximo uno por registro
+	Pendientes(ctx context.Context, limite int) ([]models.EventoOutbox, error)
+	MarcarEnviado(ctx context.Context, id int64) error
+	// Reprogramar registra un intento fallido y la hora del siguiente intento
+	Reprogramar(ctx context.Context, id int64, proximo time.Time, causa string) error
+	// MarcarFallido mueve el evento a la cola de fallidos tras agotar los reintentos
+	MarcarFallido(ctx context.Context, id int64, causa string) error
+	ListFallidos(ctx context.Context) ([]models.EventoOutbox, error)
+	// Reintentar devuelve un evento fallido a la cola de pendientes
+	Reintentar(ctx context.Context, idEvento string) error
+}
+
+// SyncRepo aplica los cambios recibidos desde otras réplicas
+type SyncRepo interface {
+	// Aplicar aplica el evento una sola vez por ID, resolviendo conflictos por versión
+	// (gana la última escritura); devuelve uno de los resultados models.Sync*
+	Aplicar(ctx context.Context, e models.EventoSync) (string, error)
+}
+
+// Store agrupa los repositorios de la aplicación
+type Store struct {
+	Estudiantes    EstudianteRepo
+	Asignaturas    AsignaturaRepo
+	Prerrequisitos PrerrequisitoRepo
+	Profesores     ProfesorRepo
+	Ciclos         CicloRepo
+	Asignaciones   AsignacionRepo
+	Matriculas     MatriculaRepo
+	Notas          NotaRepo
+	Usuarios       UsuarioRepo
+	Outbox         OutboxRepo
+	Sync           SyncRepo
+}
+
+// NewMySQLStore crea los repositorios respaldados por MySQL
+func NewMySQLStore(db *sql.DB, politica config.PoliticaCalificacion, reglas config.ReglasMatricula) *Store {
+	return &Store{
+		Estudiantes:    &mysqlEstudiantes{db: db},
+		Asignaturas:    &mysqlAsignaturas{db: db},
+		Prerrequisitos: &mysqlPrerrequisitos{db: db, politica: politica},
+		Profesores:     &mysqlProfesores{db: db},
+		Ciclos:         &mysqlCiclos{db: db},
+		Asignaciones:   &mysqlAsignaciones{db: db, politica: politica},
+		Matriculas:     &mysqlMatriculas{db: db, politica: politica, reglas: reglas},
+		Notas:          &mysqlNotas{db: db},
+		Usuarios:       &mysqlUsuarios{db: db},
+		Outbox:         &mysqlOutbox{db: db},
+		Sync:           &mysqlSync{db: db},
+	}
+}
+
+// queryer es implementado por *sql.DB y *sql.Tx
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
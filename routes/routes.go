@@ -1,9 +1,13 @@
 package routes
 
 import (
+	"encoding/json"
 	"net/http"
+	"server_estudiantes/auth"
+	"server_estudiantes/config"
 	"server_estudiantes/controllers"
 	"server_estudiantes/middleware"
+	"server_estudiantes/models"
 
 	"github.com/gorilla/mux"
 )
@@ -17,60 +21,140 @@ func SetupRoutes(
 	matriculasController *controllers.MatriculasController,
 	notasController *controllers.NotasController,
 	asignacionesController *controllers.AsignacionesController,
+	prerrequisitosController *controllers.PrerrequisitosController,
+	kardexController *controllers.KardexController,
+	authController *controllers.AuthController,
+	usuariosController *controllers.UsuariosController,
+	outboxController *controllers.OutboxController,
+	syncController *controllers.SyncController,
+	hub *controllers.Hub,
+	autenticar mux.MiddlewareFunc,
+	syncToken string,
 ) http.Handler {
 	router := mux.NewRouter()
 
+	// Respuestas JSON para rutas y métodos inexistentes
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		middleware.WriteError(w, r, http.StatusNotFound, models.CodNoEncontrado, "Ruta no encontrada", nil)
+	})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		middleware.WriteError(w, r, http.StatusMethodNotAllowed, models.CodMetodoNoPermitido, "Método no permitido", nil)
+	})
+
 	// Middleware para logging
 	router.Use(middleware.LoggerMiddleware)
 
+	// Middleware de autenticación: identifica al usuario del token de sesión
+	router.Use(autenticar)
+
+	// Políticas de acceso; el administrador siempre tiene acceso
+	personal := []string{auth.RolProfesor, auth.RolSecretaria}
+	autenticado := func(h http.HandlerFunc) http.Handler {
+		return middleware.RequireAuth(h)
+	}
+	conRol := func(h http.HandlerFunc, roles ...string) http.Handler {
+		return middleware.RequireRol(roles...)(h)
+	}
+	soloAdmin := func(h http.HandlerFunc) http.Handler {
+		return middleware.RequireRol()(h)
+	}
+	propio := func(h http.HandlerFunc, roles ...string) http.Handler {
+		return middleware.RequirePropio("id", roles...)(h)
+	}
+
 	// Ruta de estado
 	router.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(`{"status":"online","server":"estudiantes"}`))
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":      "online",
+			"server":      middleware.Origen,
+			"version":     config.Version,
+			"clientes_ws": hub.Conectados(),
+		})
 	}).Methods("GET")
 
+	// Rutas de autenticación
+	router.HandleFunc("/auth/login", authController.Login).Methods("POST")
+	router.Handle("/auth/logout", middleware.RequireAuth(http.HandlerFunc(authController.Logout))).Methods("POST")
+	router.Handle("/auth/me", middleware.RequireAuth(http.HandlerFunc(authController.Me))).Methods("GET")
+
+	// Rutas para usuarios (solo administrador)
+	router.Handle("/usuarios", soloAdmin(usuariosController.CreateUsuario)).Methods("POST")
+
+	// Receptor de cambios de otras réplicas, autenticado con el secreto compartido del middleware
+	router.Handle("/sync", middleware.RequireSyncToken(syncToken)(http.HandlerFunc(syncController.Sync))).Methods("POST")
+
+	// Rutas para eventos de sincronización fallidos (solo administrador)
+	router.Handle("/outbox/fallidos", soloAdmin(outboxController.GetEventosFallidos)).Methods("GET")
+	router.Handle("/outbox/fallidos/{id}/reintentar", soloAdmin(outboxController.ReintentarEvento)).Methods("POST")
+
 	// Rutas para estudiantes
-	router.HandleFunc("/estudiantes", estudiantesController.GetAllEstudiantes).Methods("GET")
-	router.HandleFunc("/estudiantes/{id}", estudiantesController.GetEstudiante).Methods("GET")
-	router.HandleFunc("/estudiantes", estudiantesController.CreateEstudiante).Methods("POST")
-	router.HandleFunc("/estudiantes/{id}", estudiantesController.UpdateEstudiante).Methods("PUT")
-	router.HandleFunc("/estudiantes/{id}", estudiantesController.DeleteEstudiante).Methods("DELETE")
+	router.Handle("/estudiantes", conRol(estudiantesController.GetAllEstudiantes, personal...)).Methods("GET")
+	router.Handle("/estudiantes/{id}", propio(estudiantesController.GetEstudiante, personal...)).Methods("GET")
+	router.Handle("/estudiantes", conRol(estudiantesController.CreateEstudiante, auth.RolSecretaria)).Methods("POST")
+	router.Handle("/estudiantes/{id}", propio(estudiantesController.UpdateEstudiante, auth.RolSecretaria)).Methods("PUT")
+	router.Handle("/estudiantes/{id}", conRol(estudiantesController.DeleteEstudiante, auth.RolSecretaria)).Methods("DELETE")
 
 	// Rutas para asignaturas
-	router.HandleFunc("/asignaturas", asignaturasController.GetAllAsignaturas).Methods("GET")
-	router.HandleFunc("/asignaturas/{id}", asignaturasController.GetAsignatura).Methods("GET")
+	router.Handle("/asignaturas", autenticado(asignaturasController.GetAllAsignaturas)).Methods("GET")
+	router.Handle("/asignaturas/{id}", autenticado(asignaturasController.GetAsignatura)).Methods("GET")
+	router.Handle("/asignaturas", soloAdmin(asignaturasController.CreateAsignatura)).Methods("POST")
+	router.Handle("/asignaturas/{id}", soloAdmin(asignaturasController.UpdateAsignatura)).Methods("PUT")
+	router.Handle("/asignaturas/{id}", soloAdmin(asignaturasController.DeleteAsignatura)).Methods("DELETE")
+
+	// Rutas para el plan de estudios
+	router.Handle("/plan-estudios", autenticado(prerrequisitosController.GetPlanEstudios)).Methods("GET")
+	router.Handle("/asignaturas/{id}/prerrequisitos", autenticado(prerrequisitosController.GetPrerrequisitosByAsignatura)).Methods("GET")
+	router.Handle("/asignaturas/{id}/prerrequisitos", soloAdmin(prerrequisitosController.CreatePrerrequisito)).Methods("POST")
+	router.Handle("/prerrequisitos/{id}", soloAdmin(prerrequisitosController.DeletePrerrequisito)).Methods("DELETE")
+	router.Handle("/estudiantes/{id}/asignaturas-elegibles", propio(prerrequisitosController.GetAsignaturasElegibles, personal...)).Methods("GET")
 
 	// Rutas para profesores
-	router.HandleFunc("/profesores", profesoresController.GetAllProfesores).Methods("GET")
-	router.HandleFunc("/profesores/{id}", profesoresController.GetProfesor).Methods("GET")
+	router.Handle("/profesores", autenticado(profesoresController.GetAllProfesores)).Methods("GET")
+	router.Handle("/profesores/{id}", autenticado(profesoresController.GetProfesor)).Methods("GET")
+	router.Handle("/profesores", soloAdmin(profesoresController.CreateProfesor)).Methods("POST")
+	router.Handle("/profesores/{id}", soloAdmin(profesoresController.UpdateProfesor)).Methods("PUT")
+	router.Handle("/profesores/{id}", soloAdmin(profesoresController.DeleteProfesor)).Methods("DELETE")
 
 	// Rutas para ciclos
-	router.HandleFunc("/ciclos", ciclosController.GetAllCiclos).Methods("GET")
-	router.HandleFunc("/ciclos/{id}", ciclosController.GetCiclo).Methods("GET")
+	router.Handle("/ciclos", autenticado(ciclosController.GetAllCiclos)).Methods("GET")
+	router.Handle("/ciclos/{id}", autenticado(ciclosController.GetCiclo)).Methods("GET")
+	router.Handle("/ciclos", soloAdmin(ciclosController.CreateCiclo)).Methods("POST")
+	router.Handle("/ciclos/{id}", soloAdmin(ciclosController.UpdateCiclo)).Methods("PUT")
+	router.Handle("/ciclos/{id}", soloAdmin(ciclosController.DeleteCiclo)).Methods("DELETE")
 
 	// Rutas para asignaciones
-	router.HandleFunc("/asignaciones", asignacionesController.GetAllAsignaciones).Methods("GET")
-	router.HandleFunc("/asignaciones/{id}", asignacionesController.GetAsignacion).Methods("GET")
-
-	// Rutas para matrículas
-	router.HandleFunc("/matriculas", matriculasController.GetAllMatriculas).Methods("GET")
-	router.HandleFunc("/matriculas", matriculasController.CreateMatricula).Methods("POST")
-	router.HandleFunc("/matriculas/{id}", matriculasController.GetMatricula).Methods("GET")
-	router.HandleFunc("/matriculas/{id}", matriculasController.UpdateMatricula).Methods("PUT")
-	router.HandleFunc("/api/matriculas/{id}", matriculasController.DeleteMatricula).Methods("DELETE")
-
-	// Rutas para notas
-	router.HandleFunc("/notas", notasController.GetAllNotas).Methods("GET")
-	router.HandleFunc("/notas/{id}", notasController.GetNota).Methods("GET")
-	router.HandleFunc("/notas-estudiante/{id}", notasController.GetNotasByEstudiante).Methods("GET")
+	router.Handle("/asignaciones", autenticado(asignacionesController.GetAllAsignaciones)).Methods("GET")
+	router.Handle("/asignaciones/{id}", autenticado(asignacionesController.GetAsignacion)).Methods("GET")
+	router.Handle("/asignaciones", soloAdmin(asignacionesController.CreateAsignacion)).Methods("POST")
+	router.Handle("/asignaciones/{id}", soloAdmin(asignacionesController.UpdateAsignacion)).Methods("PUT")
+	router.Handle("/asignaciones/{id}", soloAdmin(asignacionesController.DeleteAsignacion)).Methods("DELETE")
+
+	// Rutas para matrículas: los estudiantes solo gestionan las propias (verificado en el controlador)
+	router.Handle("/matriculas", conRol(matriculasController.GetAllMatriculas, personal...)).Methods("GET")
+	router.Handle("/matriculas", conRol(matriculasController.CreateMatricula, auth.RolEstudiante, auth.RolSecretaria)).Methods("POST")
+	router.Handle("/matriculas/{id}", autenticado(matriculasController.GetMatricula)).Methods("GET")
+	router.Handle("/matriculas/{id}", conRol(matriculasController.UpdateMatricula, auth.RolSecretaria)).Methods("PUT")
+	router.Handle("/matriculas/{id}", conRol(matriculasController.DeleteMatricula, auth.RolEstudiante, auth.RolSecretaria)).Methods("DELETE")
+	router.Handle("/estudiantes/{id}/matriculas", propio(matriculasController.GetMatriculasByEstudiante, personal...)).Methods("GET")
+	router.Handle("/asignaciones/{id}/matriculas", conRol(matriculasController.GetMatriculasByAsignacion, personal...)).Methods("GET")
+
+	// Rutas para notas: los profesores solo califican sus asignaciones (verificado en el controlador)
+	router.Handle("/notas", conRol(notasController.GetAllNotas, personal...)).Methods("GET")
+	router.Handle("/notas/{id}", autenticado(notasController.GetNota)).Methods("GET")
+	router.Handle("/notas/{id}", conRol(notasController.UpdateNota, auth.RolProfesor)).Methods("PUT")
+	router.Handle("/notas-estudiante/{id}", propio(notasController.GetNotasByEstudiante, personal...)).Methods("GET")
+	router.Handle("/estudiantes/{id}/kardex", propio(kardexController.GetKardex, personal...)).Methods("GET")
+	router.Handle("/asignaciones/{id}/notas", conRol(notasController.UpdateNotasByAsignacion, auth.RolProfesor)).Methods("PUT")
 
 
 	// Ruta socket
-	router.HandleFunc("/ws", controllers.WebSocketHandler)
+	router.HandleFunc("/ws", hub.HandleWebSocket)
 
 	
 	// Rutas para asignaturas disponibles
-	router.HandleFunc("/asignaturas-disponibles", asignacionesController.GetAsignaturasDisponibles).Methods("GET")
+	router.Handle("/asignaturas-disponibles", autenticado(asignacionesController.GetAsignaturasDisponibles)).Methods("GET")
+	router.Handle("/estudiantes/{id}/asignaturas-disponibles", propio(asignacionesController.GetAsignaturasDisponiblesByEstudiante, personal...)).Methods("GET")
 	// Servir archivos estáticos
 	fs := http.FileServer(http.Dir("./frontend"))
 	router.PathPrefix("/").Handler(http.StripPrefix("/", fs))
@@ -0,0 +1,489 @@
+// Package semilla carga datos de demostración desde un archivo JSON.
+//
+// La carga es idempotente: cada registro se busca por su clave natural (nombre del
+// ciclo, de la asignatura o de la persona, usuario, el par de asignaturas de un
+// prerrequisito y el trío profesor-asignatura-ciclo de una asignación) y solo se crea
+// si no existe. Los registros se crean a través de los repositorios, por lo que también
+// se encolan para sincronizarse con el middleware.
+package semilla
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"server_estudiantes/auth"
+	"server_estudiantes/models"
+	"server_estudiantes/repository"
+	"server_estudiantes/validacion"
+	"strings"
+)
+
+// Demo es el archivo de datos de demostración incluido en el binario
+//
+//go:embed demo.json
+var Demo []byte
+
+// Fixture describe el contenido de un archivo de datos de demostración
+type Fixture struct {
+	Ciclos      []Ciclo      `json:"ciclos"`
+	Asignaturas []Asignatura `json:"asignaturas"`
+	// Prerrequisitos se cargan antes que las matrículas, que deben cumplirlos
+	Prerrequisitos []Prerrequisito `json:"prerrequisitos"`
+	Profesores     []Persona       `json:"profesores"`
+	Asignaciones   []Asignacion    `json:"asignaciones"`
+	Estudiantes    []Persona       `json:"estudiantes"`
+	Matriculas     []Matricula     `json:"matriculas"`
+	Usuarios       []Usuario       `json:"usuarios"`
+}
+
+// Ciclo es un ciclo académico de la fixture
+type Ciclo struct {
+	Ciclo string `json:"ciclo" validar:"requerido,max=50"`
+}
+
+// Asignatura es una asignatura de la fixture
+type Asignatura struct {
+	Nombre string `json:"nombre_asignatura" validar:"requerido,max=100"`
+	// Sin créditos la asignatura vale uno, igual que al crearla por la API
+	Creditos       int `json:"creditos" validar:"min=0,max=30"`
+	HorasSemanales int `json:"horas_semanales" validar:"min=0,max=60"`
+}
+
+// Prerrequisito indica por nombre que una asignatura de la fixture exige aprobar otra
+type Prerrequisito struct {
+	Asignatura string `json:"asignatura" validar:"requerido"`
+	Requerida  string `json:"requerida" validar:"requerido"`
+}
+
+// Persona es un profesor o estudiante; con usuario y contraseña también se crean sus credenciales
+type Persona struct {
+	Nombre   string `json:"nombre" validar:"requerido,max=100"`
+	Usuario  string `json:"usuario" validar:"max=50"`
+	Password string `json:"password" validar:"min=8,max=128"`
+}
+
+// Asignacion vincula por nombre un profesor, una asignatura y un ciclo de la fixture
+type Asignacion struct {
+	Profesor   string `json:"profesor" validar:"requerido"`
+	Asignatura string `json:"asignatura" validar:"requerido"`
+	Ciclo      string `json:"ciclo" validar:"requerido"`
+}
+
+// Matricula inscribe a un estudiante en una asignación y, opcionalmente, registra sus notas.
+// Si indica alguna nota el registro queda calificado y las omitidas valen cero.
+type Matricula struct {
+	Estudiante string `json:"estudiante" validar:"requerido"`
+	Asignacion
+	Nota1 *float64 `json:"nota1" validar:"min=0"`
+	Nota2 *float64 `json:"nota2" validar:"min=0"`
+	Sup   *int     `json:"sup" validar:"min=0"`
+}
+
+// Usuario son las credenciales de personal administrativo
+type Usuario struct {
+	Usuario  string `json:"usuario" validar:"requerido,max=50"`
+	Password string `json:"password" validar:"requerido,min=8,max=128"`
+	Rol      string `json:"rol" validar:"requerido,oneof=secretaria|admin"`
+}
+
+// Resumen cuenta los registros creados y los que ya existían
+type Resumen struct {
+	Creados    map[string]int
+	Existentes map[string]int
+}
+
+func (r Resumen) registrar(entidad string, creado bool) {
+	if creado {
+		r.Creados[entidad]++
+	} else {
+		r.Existentes[entidad]++
+	}
+}
+
+// Leer carga una fixture desde un archivo; una ruta vacía usa los datos de demostración incluidos
+func Leer(ruta string) (Fixture, error) {
+	contenido := Demo
+	if ruta != "" {
+		var err error
+		if contenido, err = os.ReadFile(ruta); err != nil {
+			return Fixture{}, err
+		}
+	}
+	return Decodificar(contenido)
+}
+
+// Decodificar interpreta y valida el contenido de una fixture
+func Decodificar(contenido []byte) (Fixture, error) {
+	var f Fixture
+	dec := json.NewDecoder(bytes.NewReader(contenido))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return f, fmt.Errorf("fixture inválida: %w", err)
+	}
+
+	if errores := validacion.Validar(&f); len(errores) > 0 {
+		mensajes := make([]string, len(errores))
+		for i, e := range errores {
+			mensajes[i] = e.Campo + ": " + e.Mensaje
+		}
+		return f, fmt.Errorf("fixture inválida: %s", strings.Join(mensajes, "; "))
+	}
+	return f, nil
+}
+
+// cargador mantiene los IDs resueltos de cada clave natural durante una carga
+type cargador struct {
+	store        *repository.Store
+	resumen      Resumen
+	ciclos       map[string]string
+	asignaturas  map[string]string
+	profesores   map[string]string
+	estudiantes  map[string]string
+	asignaciones map[Asignacion]string
+}
+
+// Aplicar crea los registros de la fixture que aún no existen
+func Aplicar(ctx context.Context, store *repository.Store, f Fixture) (Resumen, error) {
+	c := &cargador{
+		store:        store,
+		resumen:      Resumen{Creados: map[string]int{}, Existentes: map[string]int{}},
+		ciclos:       map[string]string{},
+		asignaturas:  map[string]string{},
+		profesores:   map[string]string{},
+		estudiantes:  map[string]string{},
+		asignaciones: map[Asignacion]string{},
+	}
+
+	pasos := []func(context.Context, Fixture) error{
+		c.cargarCiclos,
+		c.cargarAsignaturas,
+		c.cargarPrerrequisitos,
+		c.cargarProfesores,
+		c.cargarAsignaciones,
+		c.cargarEstudiantes,
+		c.cargarMatriculas,
+		c.cargarUsuarios,
+	}
+	for _, paso := range pasos {
+		if err := paso(ctx, f); err != nil {
+			return c.resumen, err
+		}
+	}
+	return c.resumen, nil
+}
+
+func (c *cargador) cargarCiclos(ctx context.Context, f Fixture) error {
+	existentes, err := c.store.Ciclos.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, e := range existentes {
+		c.ciclos[e.Ciclo] = e.IDCiclo
+	}
+
+	for _, fc := range f.Ciclos {
+		_, existe := c.ciclos[fc.Ciclo]
+		if !existe {
+			nuevo := models.Ciclo{Ciclo: fc.Ciclo}
+			if err := c.store.Ciclos.Create(ctx, &nuevo); err != nil {
+				return fmt.Errorf("ciclo %s: %w", fc.Ciclo, err)
+			}
+			c.ciclos[fc.Ciclo] = nuevo.IDCiclo
+		}
+		c.resumen.registrar("ciclos", !existe)
+	}
+	return nil
+}
+
+func (c *cargador) cargarAsignaturas(ctx context.Context, f Fixture) error {
+	existentes, err := c.store.Asignaturas.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, e := range existentes {
+		c.asignaturas[e.Nombre] = e.IDAsignatura
+	}
+
+	for _, fa := range f.Asignaturas {
+		_, existe := c.asignaturas[fa.Nombre]
+		if !existe {
+			nueva := models.Asignatura{Nombre: fa.Nombre, Creditos: fa.Creditos, HorasSemanales: fa.HorasSemanales}
+			if nueva.Creditos == 0 {
+				nueva.Creditos = 1
+			}
+			if err := c.store.Asignaturas.Create(ctx, &nueva); err != nil {
+				return fmt.Errorf("asignatura %s: %w", fa.Nombre, err)
+			}
+			c.asignaturas[fa.Nombre] = nueva.IDAsignatura
+		}
+		c.resumen.registrar("asignaturas", !existe)
+	}
+	return nil
+}
+
+func (c *cargador) cargarPrerrequisitos(ctx context.Context, f Fixture) error {
+	existentes, err := c.store.Prerrequisitos.List(ctx)
+	if err != nil {
+		return err
+	}
+	pares := map[[2]string]bool{}
+	for _, e := range existentes {
+		pares[[2]string{e.IDAsignatura, e.IDAsignaturaRequerida}] = true
+	}
+
+	for _, fp := range f.Prerrequisitos {
+		idAsignatura, ok := c.asignaturas[fp.Asignatura]
+		if !ok {
+			return fmt.Errorf("prerrequisito: asignatura %q no definida", fp.Asignatura)
+		}
+		idRequerida, ok := c.asignaturas[fp.Requerida]
+		if !ok {
+			return fmt.Errorf("prerrequisito: asignatura %q no definida", fp.Requerida)
+		}
+
+		par := [2]string{idAsignatura, idRequerida}
+		existe := pares[par]
+		if !existe {
+			nuevo := models.Prerrequisito{IDAsignatura: idAsignatura, IDAsignaturaRequerida: idRequerida}
+			if err := c.store.Prerrequisitos.Create(ctx, &nuevo); err != nil {
+				return fmt.Errorf("prerrequisito %s de %s: %w", fp.Requerida, fp.Asignatura, err)
+			}
+			pares[par] = true
+		}
+		c.resumen.registrar("prerrequisitos", !existe)
+	}
+	return nil
+}
+
+func (c *cargador) cargarProfesores(ctx context.Context, f Fixture) error {
+	existentes, err := c.store.Profesores.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, e := range existentes {
+		c.profesores[e.Nombre] = e.IDProfesor
+	}
+
+	for _, fp := range f.Profesores {
+		_, existe := c.profesores[fp.Nombre]
+		if !existe {
+			nuevo := models.Profesor{Nombre: fp.Nombre}
+			if err := c.store.Profesores.Create(ctx, &nuevo); err != nil {
+				return fmt.Errorf("profesor %s: %w", fp.Nombre, err)
+			}
+			c.profesores[fp.Nombre] = nuevo.IDProfesor
+		}
+		c.resumen.registrar("profesores", !existe)
+
+		if err := c.credenciales(ctx, fp, auth.RolProfesor, c.profesores[fp.Nombre]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cargador) cargarAsignaciones(ctx context.Context, f Fixture) error {
+	for _, fa := range f.Asignaciones {
+		if _, err := c.asignacion(ctx, fa); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// asignacion resuelve, creándola si hace falta, la asignación de un trío profesor-asignatura-ciclo
+func (c *cargador) asignacion(ctx context.Context, fa Asignacion) (string, error) {
+	if id, ok := c.asignaciones[fa]; ok {
+		return id, nil
+	}
+
+	idProfesor, ok := c.profesores[fa.Profesor]
+	if !ok {
+		return "", fmt.Errorf("asignación: profesor %q no definido", fa.Profesor)
+	}
+	idAsignatura, ok := c.asignaturas[fa.Asignatura]
+	if !ok {
+		return "", fmt.Errorf("asignación: asignatura %q no definida", fa.Asignatura)
+	}
+	idCiclo, ok := c.ciclos[fa.Ciclo]
+	if !ok {
+		return "", fmt.Errorf("asignación: ciclo %q no definido", fa.Ciclo)
+	}
+
+	existentes, _, _, err := c.store.Asignaciones.List(ctx, repository.ListParams{Filtros: map[string]string{
+		"id_profesores":  idProfesor,
+		"id_asignaturas": idAsignatura,
+		"id_ciclos":      idCiclo,
+	}})
+	if err != nil {
+		return "", err
+	}
+
+	existe := len(existentes) > 0
+	if existe {
+		c.asignaciones[fa] = existentes[0].IDAsignacion
+	} else {
+		nueva := models.Asignacion{IDProfesor: idProfesor, IDAsignatura: idAsignatura, IDCiclo: idCiclo}
+		if err := c.store.Asignaciones.Create(ctx, &nueva); err != nil {
+			return "", fmt.Errorf("asignación %s/%s/%s: %w", fa.Profesor, fa.Asignatura, fa.Ciclo, err)
+		}
+		c.asignaciones[fa] = nueva.IDAsignacion
+	}
+	c.resumen.registrar("asignaciones", !existe)
+	return c.asignaciones[fa], nil
+}
+
+func (c *cargador) cargarEstudiantes(ctx context.Context, f Fixture) error {
+	for _, fe := range f.Estudiantes {
+		existentes, _, _, err := c.store.Estudiantes.List(ctx, repository.ListParams{Filtros: map[string]string{"nombre": fe.Nombre}})
+		if err != nil {
+			return err
+		}
+
+		// El filtro por nombre es parcial; solo cuenta la coincidencia exacta
+		existe := false
+		for _, e := range existentes {
+			if e.Nombre == fe.Nombre {
+				c.estudiantes[fe.Nombre], existe = e.IDEstudiante, true
+				break
+			}
+		}
+		if !existe {
+			nuevo := models.Estudiante{Nombre: fe.Nombre}
+			if err := c.store.Estudiantes.Create(ctx, &nuevo); err != nil {
+				return fmt.Errorf("estudiante %s: %w", fe.Nombre, err)
+			}
+			c.estudiantes[fe.Nombre] = nuevo.IDEstudiante
+		}
+		c.resumen.registrar("estudiantes", !existe)
+
+		if err := c.credenciales(ctx, fe, auth.RolEstudiante, c.estudiantes[fe.Nombre]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *cargador) cargarMatriculas(ctx context.Context, f Fixture) error {
+	for _, fm := range f.Matriculas {
+		idEstudiante, ok := c.estudiantes[fm.Estudiante]
+		if !ok {
+			return fmt.Errorf("matrícula: estudiante %q no definido", fm.Estudiante)
+		}
+		idAsignacion, err := c.asignacion(ctx, fm.Asignacion)
+		if err != nil {
+			return err
+		}
+
+		matriculas, err := c.store.Matriculas.ListByEstudiante(ctx, idEstudiante)
+		if err != nil {
+			return err
+		}
+		idMatricula := ""
+		for _, m := range matriculas {
+			if m.IDAsignacion == idAsignacion {
+				idMatricula = m.IDMatricula
+				break
+			}
+		}
+
+		existe := idMatricula != ""
+		if !existe {
+			nueva := models.Matricula{IDEstudiante: idEstudiante, IDAsignacion: idAsignacion}
+			if _, err := c.store.Matriculas.Create(ctx, &nueva); err != nil {
+				return fmt.Errorf("matrícula de %s en %s: %w", fm.Estudiante, fm.Asignatura, err)
+			}
+			idMatricula = nueva.IDMatricula
+		}
+		c.resumen.registrar("matriculas", !existe)
+
+		if err := c.notas(ctx, fm, idEstudiante, idMatricula); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// notas registra las calificaciones de la fixture solo si el registro sigue sin calificar,
+// para no sobrescribir cambios hechos después de una carga anterior
+func (c *cargador) notas(ctx context.Context, fm Matricula, idEstudiante, idMatricula string) error {
+	if fm.Nota1 == nil && fm.Nota2 == nil && fm.Sup == nil {
+		return nil
+	}
+
+	notas, err := c.store.Notas.ListByEstudiante(ctx, idEstudiante)
+	if err != nil {
+		return err
+	}
+	for _, n := range notas {
+		if n.IDMatricula != idMatricula {
+			continue
+		}
+		if n.Calificado {
+			c.resumen.registrar("notas", false)
+			return nil
+		}
+		n.Nota1, n.Nota2, n.Sup, n.Calificado = valorOCero(fm.Nota1), valorOCero(fm.Nota2), valorOCero(fm.Sup), true
+		if err := c.store.Notas.Update(ctx, &n); err != nil {
+			return fmt.Errorf("notas de %s en %s: %w", fm.Estudiante, fm.Asignatura, err)
+		}
+		c.resumen.registrar("notas", true)
+		return nil
+	}
+	return fmt.Errorf("notas de %s en %s: registro de notas no encontrado", fm.Estudiante, fm.Asignatura)
+}
+
+// valorOCero devuelve el valor apuntado o el valor cero de su tipo si la fixture lo omite
+func valorOCero[T any](v *T) T {
+	var cero T
+	if v == nil {
+		return cero
+	}
+	return *v
+}
+
+func (c *cargador) cargarUsuarios(ctx context.Context, f Fixture) error {
+	for _, fu := range f.Usuarios {
+		if err := c.crearUsuario(ctx, fu.Usuario, fu.Password, fu.Rol, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// credenciales crea el usuario de un profesor o estudiante si la fixture lo define
+func (c *cargador) credenciales(ctx context.Context, p Persona, rol, idReferencia string) error {
+	if p.Usuario == "" {
+		return nil
+	}
+	if p.Password == "" {
+		return fmt.Errorf("usuario %s: la contraseña es requerida", p.Usuario)
+	}
+	return c.crearUsuario(ctx, p.Usuario, p.Password, rol, idReferencia)
+}
+
+// crearUsuario crea las credenciales si el nombre de usuario aún no existe
+func (c *cargador) crearUsuario(ctx context.Context, usuario, password, rol, idReferencia string) error {
+	_, err := c.store.Usuarios.GetByUsuario(ctx, usuario)
+	if err == nil {
+		c.resumen.registrar("usuarios", false)
+		return nil
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return err
+	}
+
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+	u := models.Usuario{Usuario: usuario, PasswordHash: hash, Rol: rol, IDReferencia: idReferencia}
+	if err := c.store.Usuarios.Create(ctx, &u); err != nil {
+		return fmt.Errorf("usuario %s: %w", usuario, err)
+	}
+	c.resumen.registrar("usuarios", true)
+	return nil
+}
@@ -0,0 +1,265 @@
+// Package validacion aplica reglas declarativas a las estructuras de entrada de la API.
+//
+// Las reglas se declaran en la etiqueta `validar` de cada campo, separadas por comas:
+//
+//	Nombre string `json:"nombre" validar:"requerido,max=100"`
+//
+// Reglas disponibles:
+//   - requerido: el texto no puede estar vacío ni el número ser cero
+//   - min=N, max=N: longitud de un texto o de una lista, o valor de un número
+//   - id: identificador de 20 caracteres hexadecimales generado por config.GenerateID
+//   - oneof=a|b|c: el valor debe ser uno de los indicados
+//
+// Los campos de tipo estructura o lista de estructuras se validan de forma recursiva.
+package validacion
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"server_estudiantes/models"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Nombres de las reglas, devueltos en models.ErrorCampo.Regla
+const (
+	ReglaRequerido = "requerido"
+	ReglaMin       = "min"
+	ReglaMax       = "max"
+	ReglaID        = "id"
+	ReglaOneOf     = "oneof"
+)
+
+// formatoID corresponde a los IDs generados por config.GenerateID
+var formatoID = regexp.MustCompile(`^[0-9a-f]{20}$`)
+
+// regla es una regla ya interpretada de la etiqueta de un campo
+type regla struct {
+	nombre   string
+	limite   float64
+	opciones []string
+}
+
+// campo describe un campo validable de una estructura
+type campo struct {
+	indice int
+	nombre string
+	reglas []regla
+	// embebido indica una estructura anónima cuyos campos se reportan sin prefijo
+	embebido bool
+}
+
+// camposPorTipo guarda las reglas interpretadas de cada tipo de estructura
+var camposPorTipo sync.Map
+
+// Validar aplica las reglas de v, que puede ser una estructura, un puntero a ella
+// o una lista de estructuras, y devuelve todos los errores encontrados
+func Validar(v interface{}) []models.ErrorCampo {
+	var errores []models.ErrorCampo
+	validarValor(reflect.ValueOf(v), "", &errores)
+	return errores
+}
+
+// Campo crea un error de validación para un campo; permite a los controladores
+// agregar reglas que dependen de la configuración o de otros campos
+func Campo(nombre, regla, mensaje string) models.ErrorCampo {
+	return models.ErrorCampo{Campo: nombre, Regla: regla, Mensaje: mensaje}
+}
+
+// validarValor recorre punteros, listas y estructuras acumulando los errores
+func validarValor(v reflect.Value, prefijo string, errores *[]models.ErrorCampo) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			validarValor(v.Index(i), fmt.Sprintf("%s[%d]", prefijo, i), errores)
+		}
+	case reflect.Struct:
+		for _, c := range camposDe(v.Type()) {
+			nombre := c.nombre
+			if c.embebido {
+				nombre = prefijo
+			} else if prefijo != "" {
+				nombre = prefijo + "." + nombre
+			}
+			valor := v.Field(c.indice)
+			for _, r := range c.reglas {
+				if msg := r.aplicar(valor); msg != "" {
+					*errores = append(*errores, Campo(nombre, r.nombre, msg))
+					// Un campo requerido vacío no se sigue evaluando
+					if r.nombre == ReglaRequerido {
+						break
+					}
+				}
+			}
+			validarValor(valor, nombre, errores)
+		}
+	}
+}
+
+// camposDe devuelve las reglas de los campos de un tipo, interpretándolas una sola vez
+func camposDe(t reflect.Type) []campo {
+	if c, ok := camposPorTipo.Load(t); ok {
+		return c.([]campo)
+	}
+
+	var campos []campo
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		etiqueta := f.Tag.Get("validar")
+		if etiqueta == "" && !contieneEstructuras(f.Type) {
+			continue
+		}
+		campos = append(campos, campo{
+			indice:   i,
+			nombre:   nombreJSON(f),
+			reglas:   interpretar(t, f.Name, etiqueta),
+			embebido: f.Anonymous && f.Tag.Get("json") == "",
+		})
+	}
+
+	camposPorTipo.Store(t, campos)
+	return campos
+}
+
+// contieneEstructuras indica si un tipo debe recorrerse para validar estructuras anidadas
+func contieneEstructuras(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct
+}
+
+// nombreJSON devuelve el nombre con el que el cliente envía el campo
+func nombreJSON(f reflect.StructField) string {
+	nombre := strings.Split(f.Tag.Get("json"), ",")[0]
+	if nombre == "" || nombre == "-" {
+		return f.Name
+	}
+	return nombre
+}
+
+// interpretar convierte la etiqueta de un campo en reglas; una etiqueta mal escrita es
+// un error de programación y se detecta en la primera solicitud que la usa
+func interpretar(t reflect.Type, nombreCampo, etiqueta string) []regla {
+	var reglas []regla
+	for _, parte := range strings.Split(etiqueta, ",") {
+		parte = strings.TrimSpace(parte)
+		if parte == "" {
+			continue
+		}
+		nombre, arg, _ := strings.Cut(parte, "=")
+
+		r := regla{nombre: nombre}
+		switch nombre {
+		case ReglaRequerido, ReglaID:
+		case ReglaMin, ReglaMax:
+			limite, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				panic(fmt.Sprintf("validacion: límite inválido en %s.%s: %q", t.Name(), nombreCampo, parte))
+			}
+			r.limite = limite
+		case ReglaOneOf:
+			r.opciones = strings.Split(arg, "|")
+		default:
+			panic(fmt.Sprintf("validacion: regla desconocida en %s.%s: %q", t.Name(), nombreCampo, parte))
+		}
+		reglas = append(reglas, r)
+	}
+	return reglas
+}
+
+// aplicar evalúa la regla sobre un valor y devuelve el mensaje de error, o "" si es válido
+func (r regla) aplicar(v reflect.Value) string {
+	if r.nombre == ReglaRequerido {
+		if esVacio(v) {
+			return "El campo es requerido"
+		}
+		return ""
+	}
+
+	// Un campo opcional enviado como puntero solo se evalúa si llegó
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch r.nombre {
+	case ReglaMin, ReglaMax:
+		return r.limites(v)
+	case ReglaID:
+		if v.Kind() == reflect.String && v.String() != "" && !formatoID.MatchString(v.String()) {
+			return "Debe ser un ID de 20 caracteres hexadecimales"
+		}
+	case ReglaOneOf:
+		if v.Kind() == reflect.String && v.String() != "" {
+			for _, o := range r.opciones {
+				if v.String() == o {
+					return ""
+				}
+			}
+			return fmt.Sprintf("Debe ser uno de: %s", strings.Join(r.opciones, ", "))
+		}
+	}
+	return ""
+}
+
+// limites evalúa las reglas min y max según el tipo del campo
+func (r regla) limites(v reflect.Value) string {
+	var valor float64
+	var unidad string
+	switch v.Kind() {
+	case reflect.String:
+		// Un texto opcional vacío no se evalúa; la regla requerido cubre ese caso
+		if v.String() == "" {
+			return ""
+		}
+		valor, unidad = float64(utf8.RuneCountInString(v.String())), "caracteres"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		valor, unidad = float64(v.Len()), "elementos"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		valor = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		valor = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		valor = v.Float()
+	default:
+		return ""
+	}
+
+	if r.nombre == ReglaMin && valor < r.limite {
+		if unidad != "" {
+			return fmt.Sprintf("Debe tener al menos %g %s", r.limite, unidad)
+		}
+		return fmt.Sprintf("Debe ser mayor o igual a %g", r.limite)
+	}
+	if r.nombre == ReglaMax && valor > r.limite {
+		if unidad != "" {
+			return fmt.Sprintf("Debe tener como máximo %g %s", r.limite, unidad)
+		}
+		return fmt.Sprintf("Debe ser menor o igual a %g", r.limite)
+	}
+	return ""
+}
+
+// esVacio indica si un valor no fue enviado; los textos con solo espacios cuentan como vacíos
+func esVacio(v reflect.Value) bool {
+	if v.Kind() == reflect.String {
+		return strings.TrimSpace(v.String()) == ""
+	}
+	return v.IsZero()
+}
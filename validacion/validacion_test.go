@@ -0,0 +1,102 @@
+package validacion
+
+import (
+	"reflect"
+	"server_estudiantes/models"
+	"testing"
+)
+
+type entradaPrueba struct {
+	Nombre string   `json:"nombre" validar:"requerido,min=3,max=5"`
+	Edad   int      `json:"edad" validar:"min=1,max=99"`
+	Nota   *float64 `json:"nota" validar:"min=0,max=10"`
+	ID     string   `json:"id" validar:"id"`
+	Rol    string   `json:"rol" validar:"oneof=admin|profesor"`
+	Lista  []string `json:"lista" validar:"max=2"`
+}
+
+type DetallePrueba struct {
+	Valor int `json:"valor" validar:"requerido"`
+}
+
+type anidadaPrueba struct {
+	DetallePrueba
+	Detalles []DetallePrueba `json:"detalles"`
+	Interno  DetallePrueba   `json:"interno"`
+}
+
+func valida() entradaPrueba {
+	return entradaPrueba{Nombre: "Ana", Edad: 20, ID: "0123456789abcdef0123", Rol: "admin"}
+}
+
+func TestValidar(t *testing.T) {
+	nota := 11.0
+	casos := []struct {
+		nombre    string
+		modificar func(*entradaPrueba)
+		errores   []models.ErrorCampo
+	}{
+		{"válida", func(e *entradaPrueba) {}, nil},
+		{"requerido vacío no evalúa más reglas", func(e *entradaPrueba) { e.Nombre = "" },
+			[]models.ErrorCampo{Campo("nombre", ReglaRequerido, "El campo es requerido")}},
+		{"requerido con solo espacios", func(e *entradaPrueba) { e.Nombre = "   " },
+			[]models.ErrorCampo{Campo("nombre", ReglaRequerido, "El campo es requerido")}},
+		{"min de texto", func(e *entradaPrueba) { e.Nombre = "Al" },
+			[]models.ErrorCampo{Campo("nombre", ReglaMin, "Debe tener al menos 3 caracteres")}},
+		{"max de texto cuenta caracteres", func(e *entradaPrueba) { e.Nombre = "Núñez" }, nil},
+		{"max de texto", func(e *entradaPrueba) { e.Nombre = "Alberto" },
+			[]models.ErrorCampo{Campo("nombre", ReglaMax, "Debe tener como máximo 5 caracteres")}},
+		{"min de número", func(e *entradaPrueba) { e.Edad = 0 },
+			[]models.ErrorCampo{Campo("edad", ReglaMin, "Debe ser mayor o igual a 1")}},
+		{"max de número", func(e *entradaPrueba) { e.Edad = 100 },
+			[]models.ErrorCampo{Campo("edad", ReglaMax, "Debe ser menor o igual a 99")}},
+		{"puntero enviado", func(e *entradaPrueba) { e.Nota = &nota },
+			[]models.ErrorCampo{Campo("nota", ReglaMax, "Debe ser menor o igual a 10")}},
+		{"id con formato inválido", func(e *entradaPrueba) { e.ID = "xyz" },
+			[]models.ErrorCampo{Campo("id", ReglaID, "Debe ser un ID de 20 caracteres hexadecimales")}},
+		{"id opcional vacío", func(e *entradaPrueba) { e.ID = "" }, nil},
+		{"oneof fuera de las opciones", func(e *entradaPrueba) { e.Rol = "root" },
+			[]models.ErrorCampo{Campo("rol", ReglaOneOf, "Debe ser uno de: admin, profesor")}},
+		{"max de lista", func(e *entradaPrueba) { e.Lista = []string{"a", "b", "c"} },
+			[]models.ErrorCampo{Campo("lista", ReglaMax, "Debe tener como máximo 2 elementos")}},
+	}
+
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			e := valida()
+			c.modificar(&e)
+			if got := Validar(&e); !reflect.DeepEqual(got, c.errores) {
+				t.Errorf("Validar() = %v, se esperaba %v", got, c.errores)
+			}
+		})
+	}
+}
+
+func TestValidarAnidadas(t *testing.T) {
+	v := anidadaPrueba{Detalles: []DetallePrueba{{Valor: 1}, {}}}
+	esperados := []models.ErrorCampo{
+		Campo("valor", ReglaRequerido, "El campo es requerido"),
+		Campo("detalles[1].valor", ReglaRequerido, "El campo es requerido"),
+		Campo("interno.valor", ReglaRequerido, "El campo es requerido"),
+	}
+	if got := Validar(v); !reflect.DeepEqual(got, esperados) {
+		t.Errorf("Validar() = %v, se esperaba %v", got, esperados)
+	}
+
+	lista := []DetallePrueba{{Valor: 1}, {}}
+	esperados = []models.ErrorCampo{Campo("[1].valor", ReglaRequerido, "El campo es requerido")}
+	if got := Validar(lista); !reflect.DeepEqual(got, esperados) {
+		t.Errorf("Validar(lista) = %v, se esperaba %v", got, esperados)
+	}
+}
+
+func TestReglaDesconocida(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("una regla desconocida debería provocar panic")
+		}
+	}()
+	Validar(struct {
+		Campo string `validar:"requerida"`
+	}{})
+}